@@ -0,0 +1,369 @@
+package bybit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MarginMode is the margin mode of a unified trading account.
+type MarginMode string
+
+// Margin modes.
+const (
+	MarginModeRegular   MarginMode = "REGULAR_MARGIN"
+	MarginModeIsolated  MarginMode = "ISOLATED_MARGIN"
+	MarginModePortfolio MarginMode = "PORTFOLIO_MARGIN"
+)
+
+// UnifiedMarginStatus is the account type reported by account/info.
+type UnifiedMarginStatus int
+
+// Unified margin statuses.
+const (
+	UnifiedMarginStatusClassic UnifiedMarginStatus = 1
+	UnifiedMarginStatusUTA1    UnifiedMarginStatus = 3
+	UnifiedMarginStatusUTA1Pro UnifiedMarginStatus = 4
+	UnifiedMarginStatusUTA2    UnifiedMarginStatus = 5
+	UnifiedMarginStatusUTA2Pro UnifiedMarginStatus = 6
+)
+
+func (s UnifiedMarginStatus) String() string {
+	switch s {
+	case UnifiedMarginStatusClassic:
+		return "classic"
+	case UnifiedMarginStatusUTA1:
+		return "UTA 1.0"
+	case UnifiedMarginStatusUTA1Pro:
+		return "UTA 1.0 pro"
+	case UnifiedMarginStatusUTA2:
+		return "UTA 2.0"
+	case UnifiedMarginStatusUTA2Pro:
+		return "UTA 2.0 pro"
+	}
+	return fmt.Sprintf("unknown (%d)", int(s))
+}
+
+// IsUnified reports whether the account is a unified trading account.
+func (s UnifiedMarginStatus) IsUnified() bool {
+	return s >= UnifiedMarginStatusUTA1
+}
+
+// AccountInfo is the result of account/info.
+type AccountInfo struct {
+	UnifiedMarginStatus UnifiedMarginStatus `json:"unifiedMarginStatus"`
+	MarginMode          MarginMode          `json:"marginMode"`
+	IsMasterTrader      bool                `json:"isMasterTrader"`
+	SpotHedgingStatus   string              `json:"spotHedgingStatus"`
+	DCPStatus           string              `json:"dcpStatus"`
+	TimeWindow          int                 `json:"timeWindow"`
+	SMPGroup            int                 `json:"smpGroup"`
+	UpdatedTime         string              `json:"updatedTime"`
+}
+
+// AccountConfig returns the margin mode and account type of the account.
+func (c *Client) AccountConfig() (*AccountInfo, error) {
+	return accountInfoFromResponse(c.GetAccountInfo())
+}
+
+func accountInfoFromResponse(res map[string]interface{}, err error) (*AccountInfo, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	var info AccountInfo
+	if err := decodeResult(res, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// MarginModeReason explains why a margin mode switch was rejected.
+type MarginModeReason struct {
+	ReasonCode string `json:"reasonCode"`
+	ReasonMsg  string `json:"reasonMsg"`
+}
+
+// MarginModeError is returned by SwitchMarginMode when Bybit rejects the
+// switch, typically because of open positions or orders.
+type MarginModeError struct {
+	*APIError
+	Reasons []MarginModeReason
+}
+
+func (e *MarginModeError) Error() string {
+	msgs := make([]string, 0, len(e.Reasons))
+	for _, r := range e.Reasons {
+		msgs = append(msgs, r.ReasonMsg)
+	}
+	return fmt.Sprintf("%s: %s", e.APIError.Error(), strings.Join(msgs, "; "))
+}
+
+func (e *MarginModeError) Unwrap() error {
+	return e.APIError
+}
+
+// SwitchMarginMode sets the margin mode of a unified account. A rejected
+// switch returns a *MarginModeError listing the reasons.
+func (c *Client) SwitchMarginMode(mode MarginMode) error {
+	return marginModeFromResponse(c.SetMarginMode(marginModeParams(mode)))
+}
+
+func marginModeParams(mode MarginMode) map[string]interface{} {
+	return map[string]interface{}{"setMarginMode": string(mode)}
+}
+
+func marginModeFromResponse(res map[string]interface{}, err error) error {
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Reasons []MarginModeReason `json:"reasons"`
+	}
+	_, err = checkResponse(res)
+	if apiErr, ok := err.(*APIError); ok {
+		if decodeValue(res["result"], &result) == nil && len(result.Reasons) > 0 {
+			return &MarginModeError{APIError: apiErr, Reasons: result.Reasons}
+		}
+	}
+	return err
+}
+
+// SetSpotHedgingEnabled turns spot hedging on or off for portfolio margin.
+func (c *Client) SetSpotHedgingEnabled(on bool) error {
+	return errorFromResponse(c.SetSpotHedging(spotHedgingParams(on)))
+}
+
+func spotHedgingParams(on bool) map[string]interface{} {
+	return map[string]interface{}{"setHedgingMode": onOff(on)}
+}
+
+// Collateral switch values.
+const (
+	CollateralOn  = "ON"
+	CollateralOff = "OFF"
+)
+
+// CollateralSwitch turns a coin on or off as margin collateral.
+type CollateralSwitch struct {
+	Coin             string `json:"coin"`
+	CollateralSwitch string `json:"collateralSwitch"`
+}
+
+// CollateralInfo is an item of account/collateral-info.
+type CollateralInfo struct {
+	Currency           string `json:"currency"`
+	HourlyBorrowRate   string `json:"hourlyBorrowRate"`
+	MaxBorrowingAmount string `json:"maxBorrowingAmount"`
+	FreeBorrowingLimit string `json:"freeBorrowingLimit"`
+	FreeBorrowAmount   string `json:"freeBorrowAmount"`
+	BorrowAmount       string `json:"borrowAmount"`
+	OtherBorrowAmount  string `json:"otherBorrowAmount"`
+	AvailableToBorrow  string `json:"availableToBorrow"`
+	Borrowable         bool   `json:"borrowable"`
+	BorrowUsageRate    string `json:"borrowUsageRate"`
+	MarginCollateral   bool   `json:"marginCollateral"`
+	CollateralSwitch   bool   `json:"collateralSwitch"`
+	CollateralRatio    string `json:"collateralRatio"`
+}
+
+// Collateral returns the collateral settings of every coin, or of one coin
+// when currency is set.
+func (c *Client) Collateral(currency string) ([]CollateralInfo, error) {
+	return collateralFromResponse(c.GetCollateralInfo(collateralParams(currency)))
+}
+
+func collateralParams(currency string) map[string]interface{} {
+	params := map[string]interface{}{}
+	if currency != "" {
+		params["currency"] = currency
+	}
+	return params
+}
+
+func collateralFromResponse(res map[string]interface{}, err error) ([]CollateralInfo, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	var list []CollateralInfo
+	_, err = decodeList(res, &list)
+	return list, err
+}
+
+// SetCollateral turns a coin on or off as margin collateral.
+func (c *Client) SetCollateral(coin string, on bool) error {
+	return errorFromResponse(c.SetCollateralCoin(collateralSwitchParams(coin, on)))
+}
+
+func collateralSwitchParams(coin string, on bool) map[string]interface{} {
+	return map[string]interface{}{
+		"coin":             coin,
+		"collateralSwitch": onOff(on),
+	}
+}
+
+// SetCollateralBatch switches several coins at once and returns the
+// applied switches.
+func (c *Client) SetCollateralBatch(switches []CollateralSwitch) ([]CollateralSwitch, error) {
+	request := make([]map[string]interface{}, 0, len(switches))
+	for _, s := range switches {
+		request = append(request, map[string]interface{}{
+			"coin":             s.Coin,
+			"collateralSwitch": s.CollateralSwitch,
+		})
+	}
+
+	res, err := c.BatchSetCollateralCoin(map[string]interface{}{"request": request})
+	if err != nil {
+		return nil, err
+	}
+
+	var list []CollateralSwitch
+	_, err = decodeList(res, &list)
+	return list, err
+}
+
+// BorrowRecord is an item of account/borrow-history.
+type BorrowRecord struct {
+	Currency                  string `json:"currency"`
+	CreatedTime               int64  `json:"createdTime"`
+	BorrowCost                string `json:"borrowCost"`
+	HourlyBorrowRate          string `json:"hourlyBorrowRate"`
+	InterestBearingBorrowSize string `json:"InterestBearingBorrowSize"`
+	CostExemption             string `json:"costExemption"`
+	BorrowAmount              string `json:"borrowAmount"`
+	UnrealisedLoss            string `json:"unrealisedLoss"`
+	FreeBorrowedAmount        string `json:"freeBorrowedAmount"`
+}
+
+// BorrowHistoryQuery filters account/borrow-history. All fields are
+// optional; the range may not exceed 30 days.
+type BorrowHistoryQuery struct {
+	Currency string
+	Start    time.Time
+	End      time.Time
+	Limit    int
+	Cursor   string
+}
+
+// BorrowHistory returns a page of interest records, newest first, and the
+// cursor of the next page.
+func (c *Client) BorrowHistory(q BorrowHistoryQuery) ([]BorrowRecord, string, error) {
+	return borrowHistoryFromResponse(c.GetBorrowHistory(q.params()))
+}
+
+func (q BorrowHistoryQuery) params() map[string]interface{} {
+	params := map[string]interface{}{}
+	if q.Currency != "" {
+		params["currency"] = q.Currency
+	}
+	if !q.Start.IsZero() {
+		params["startTime"] = q.Start.UnixMilli()
+	}
+	if !q.End.IsZero() {
+		params["endTime"] = q.End.UnixMilli()
+	}
+	if q.Limit > 0 {
+		params["limit"] = q.Limit
+	}
+	if q.Cursor != "" {
+		params["cursor"] = q.Cursor
+	}
+	return params
+}
+
+func borrowHistoryFromResponse(res map[string]interface{}, err error) ([]BorrowRecord, string, error) {
+	if err != nil {
+		return nil, "", err
+	}
+
+	var list []BorrowRecord
+	cursor, err := decodeList(res, &list)
+	return list, cursor, err
+}
+
+// UTA upgrade statuses.
+const (
+	UTAUpgradeFail    = "FAIL"
+	UTAUpgradeProcess = "PROCESS"
+	UTAUpgradeSuccess = "SUCCESS"
+)
+
+// UTAUpgrade is the result of account/upgrade-to-uta. Messages explain a
+// FAIL status.
+type UTAUpgrade struct {
+	Status   string
+	Messages []string
+}
+
+// UpgradeToUnified starts the upgrade of the account to a unified trading
+// account. A PROCESS status means the upgrade is still running; poll
+// AccountConfig until UnifiedMarginStatus reports a unified account.
+func (c *Client) UpgradeToUnified() (*UTAUpgrade, error) {
+	res, err := c.UpgradeToUTA()
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		UnifiedUpdateStatus string `json:"unifiedUpdateStatus"`
+		UnifiedUpdateMsg    struct {
+			Msg []string `json:"msg"`
+		} `json:"unifiedUpdateMsg"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return nil, err
+	}
+	return &UTAUpgrade{Status: result.UnifiedUpdateStatus, Messages: result.UnifiedUpdateMsg.Msg}, nil
+}
+
+// FeeRate is an item of account/fee-rate.
+type FeeRate struct {
+	Symbol       string `json:"symbol"`
+	BaseCoin     string `json:"baseCoin"`
+	TakerFeeRate string `json:"takerFeeRate"`
+	MakerFeeRate string `json:"makerFeeRate"`
+}
+
+// FeeRates returns the account's trading fee rates of a category. symbol
+// is optional for spot, linear and inverse; options are returned per base
+// coin.
+func (c *Client) FeeRates(category, symbol string) ([]FeeRate, error) {
+	params := map[string]interface{}{"category": category}
+	if symbol != "" {
+		params["symbol"] = symbol
+	}
+
+	res, err := c.GetFeeRate(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []FeeRate
+	_, err = decodeList(res, &list)
+	return list, err
+}
+
+// AccountInstruments returns the instruments the account may trade, with
+// account-specific limits, following nextPageCursor.
+func (c *Client) AccountInstruments(q InstrumentsQuery) ([]Instrument, error) {
+	return instrumentPages(c.GetAccountInstrumentsInfo, q.params(200))
+}
+
+// errorFromResponse reduces a response without a useful result to its error.
+func errorFromResponse(res map[string]interface{}, err error) error {
+	if err != nil {
+		return err
+	}
+	_, err = checkResponse(res)
+	return err
+}
+
+func onOff(on bool) string {
+	if on {
+		return CollateralOn
+	}
+	return CollateralOff
+}
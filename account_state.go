@@ -0,0 +1,530 @@
+package bybit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Account state event types passed to AccountState.OnChange callbacks.
+const (
+	AccountEventPosition  = "position"
+	AccountEventOrder     = "order"
+	AccountEventWallet    = "wallet"
+	AccountEventExecution = "execution"
+	AccountEventReconcile = "reconcile"
+)
+
+// AccountSource is the REST API used to bootstrap and reconcile an
+// AccountState. Both *Client and *DemoClient implement it.
+type AccountSource interface {
+	GetPositions(params map[string]interface{}) (map[string]interface{}, error)
+	GetOpenOrders(params map[string]interface{}) (map[string]interface{}, error)
+	GetWalletBalance(params map[string]interface{}) (map[string]interface{}, error)
+}
+
+// AccountEvent describes a change applied to an AccountState.
+type AccountEvent struct {
+	Type string
+	// Key identifies the item: "category:symbol:positionIdx" for positions,
+	// the orderId for orders and the accountType for wallets.
+	Key     string
+	Data    map[string]interface{}
+	Removed bool
+}
+
+// AccountStateConfig configures an AccountState.
+type AccountStateConfig struct {
+	// Categories to track. Defaults to linear.
+	Categories []string
+	// SettleCoin is required by Bybit to list linear and inverse positions
+	// and orders without a symbol. Defaults to USDT.
+	SettleCoin string
+	// AccountType for the wallet balance. Defaults to UNIFIED.
+	AccountType string
+	// ReconcileInterval is how often the state is refreshed from REST to
+	// heal missed messages. Defaults to 1 minute; negative disables it.
+	ReconcileInterval time.Duration
+}
+
+// AccountState keeps positions, open orders and wallet balances in memory.
+// It is bootstrapped from REST by Start, updated by passing private stream
+// messages to Handle and periodically reconciled against REST.
+// All query methods return copies and are safe for concurrent use.
+type AccountState struct {
+	source    AccountSource
+	config    AccountStateConfig
+	positions map[string]map[string]interface{}
+	orders    map[string]map[string]interface{}
+	wallets   map[string]map[string]interface{}
+	// removedPositions and removedOrders remember items the stream removed,
+	// so a snapshot requested before the removal cannot restore them.
+	removedPositions map[string]tombstone
+	removedOrders    map[string]tombstone
+	// positionsSeen, ordersSeen and walletsSeen hold the local time in
+	// milliseconds each item was last applied from the stream, compared
+	// with the local time a snapshot was requested.
+	positionsSeen map[string]int64
+	ordersSeen    map[string]int64
+	walletsSeen   map[string]int64
+	callbacks     []func(AccountEvent)
+	running       bool
+	stop          chan struct{}
+	mu            sync.RWMutex
+}
+
+// tombstone records a removal: updated is the item's updatedTime, received
+// the local time the removal was applied, both in milliseconds.
+type tombstone struct {
+	updated  int64
+	received int64
+}
+
+// terminalOrderStatuses are order statuses after which an order is no
+// longer open.
+var terminalOrderStatuses = map[string]bool{
+	"Filled":                  true,
+	"Cancelled":               true,
+	"Rejected":                true,
+	"PartiallyFilledCanceled": true,
+	"Deactivated":             true,
+}
+
+// NewAccountState creates an account state backed by a REST source.
+func NewAccountState(source AccountSource, config AccountStateConfig) *AccountState {
+	if len(config.Categories) == 0 {
+		config.Categories = []string{"linear"}
+	}
+	if config.SettleCoin == "" {
+		config.SettleCoin = "USDT"
+	}
+	if config.AccountType == "" {
+		config.AccountType = "UNIFIED"
+	}
+	if config.ReconcileInterval == 0 {
+		config.ReconcileInterval = time.Minute
+	}
+
+	return &AccountState{
+		source:    source,
+		config:    config,
+		positions: make(map[string]map[string]interface{}),
+		orders:    make(map[string]map[string]interface{}),
+		wallets:   make(map[string]map[string]interface{}),
+
+		removedPositions: make(map[string]tombstone),
+		removedOrders:    make(map[string]tombstone),
+		positionsSeen:    make(map[string]int64),
+		ordersSeen:       make(map[string]int64),
+		walletsSeen:      make(map[string]int64),
+	}
+}
+
+// OnChange registers a callback fired after every applied change. Callbacks
+// run on the goroutine that applied the change and must not block.
+func (as *AccountState) OnChange(callback func(AccountEvent)) {
+	as.mu.Lock()
+	as.callbacks = append(as.callbacks, callback)
+	as.mu.Unlock()
+}
+
+// Start loads the initial state from REST and starts periodic
+// reconciliation. Subscribe to the private topics before calling Start so
+// no update is lost between the snapshot and the stream. It returns an
+// error if the state is already started; call Stop first to restart it.
+func (as *AccountState) Start() error {
+	as.mu.Lock()
+	if as.running {
+		as.mu.Unlock()
+		return fmt.Errorf("account state already started")
+	}
+	as.running = true
+	as.mu.Unlock()
+
+	if err := as.Reconcile(); err != nil {
+		as.mu.Lock()
+		as.running = false
+		as.mu.Unlock()
+		return err
+	}
+
+	if as.config.ReconcileInterval > 0 {
+		as.mu.Lock()
+		as.stop = make(chan struct{})
+		stop := as.stop
+		as.mu.Unlock()
+
+		go as.reconcileLoop(stop)
+	}
+
+	return nil
+}
+
+// Stop ends periodic reconciliation.
+func (as *AccountState) Stop() {
+	as.mu.Lock()
+	if as.stop != nil {
+		close(as.stop)
+		as.stop = nil
+	}
+	as.running = false
+	as.mu.Unlock()
+}
+
+func (as *AccountState) reconcileLoop(stop chan struct{}) {
+	ticker := time.NewTicker(as.config.ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			as.Reconcile()
+		}
+	}
+}
+
+// Reconcile fetches positions, open orders and wallet balances from REST
+// and merges them into the state. Items updated by the stream while the
+// request was in flight are kept.
+func (as *AccountState) Reconcile() error {
+	started := time.Now().UnixMilli()
+
+	positions := make(map[string]map[string]interface{})
+	orders := make(map[string]map[string]interface{})
+
+	for _, category := range as.config.Categories {
+		list, err := as.fetchAll(as.source.GetPositions, as.listParams(category, 200))
+		if err != nil {
+			return fmt.Errorf("fetch %s positions: %w", category, err)
+		}
+		for _, item := range list {
+			if position, ok := item.(map[string]interface{}); ok && !isZeroSize(position["size"]) {
+				if _, ok := position["category"]; !ok {
+					position["category"] = category
+				}
+				positions[positionKey(position)] = position
+			}
+		}
+
+		list, err = as.fetchAll(as.source.GetOpenOrders, as.listParams(category, 50))
+		if err != nil {
+			return fmt.Errorf("fetch %s open orders: %w", category, err)
+		}
+		for _, item := range list {
+			if order, ok := item.(map[string]interface{}); ok {
+				if _, ok := order["category"]; !ok {
+					order["category"] = category
+				}
+				if id, _ := order["orderId"].(string); id != "" {
+					orders[id] = order
+				}
+			}
+		}
+	}
+
+	res, err := as.source.GetWalletBalance(map[string]interface{}{
+		"accountType": as.config.AccountType,
+	})
+	if err != nil {
+		return fmt.Errorf("fetch wallet balance: %w", err)
+	}
+	walletList, _, err := resultList(res)
+	if err != nil {
+		return fmt.Errorf("fetch wallet balance: %w", err)
+	}
+
+	wallets := make(map[string]map[string]interface{})
+	for _, item := range walletList {
+		if wallet, ok := item.(map[string]interface{}); ok {
+			accountType, _ := wallet["accountType"].(string)
+			wallets[accountType] = wallet
+		}
+	}
+
+	as.mu.Lock()
+	as.positions = mergeSnapshot(as.positions, positions, as.removedPositions, as.positionsSeen, started)
+	as.orders = mergeSnapshot(as.orders, orders, as.removedOrders, as.ordersSeen, started)
+	// The snapshot only covers the configured account type, so other
+	// wallets the stream reported are kept as they are.
+	for accountType, wallet := range mergeSnapshot(as.wallets, wallets, nil, as.walletsSeen, started) {
+		as.wallets[accountType] = wallet
+	}
+	callbacks := as.callbacks
+	as.mu.Unlock()
+
+	notify(callbacks, AccountEvent{Type: AccountEventReconcile})
+	return nil
+}
+
+func (as *AccountState) listParams(category string, limit int) map[string]interface{} {
+	params := map[string]interface{}{
+		"category": category,
+		"limit":    limit,
+	}
+	if category == "linear" || category == "inverse" {
+		params["settleCoin"] = as.config.SettleCoin
+	}
+	return params
+}
+
+// fetchAll follows nextPageCursor until every page has been read.
+func (as *AccountState) fetchAll(fetch func(map[string]interface{}) (map[string]interface{}, error), params map[string]interface{}) ([]interface{}, error) {
+	var all []interface{}
+	for {
+		res, err := fetch(params)
+		if err != nil {
+			return nil, err
+		}
+		list, cursor, err := resultList(res)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, list...)
+		if cursor == "" || len(list) == 0 {
+			return all, nil
+		}
+		params["cursor"] = cursor
+	}
+}
+
+// Handle applies a private stream message. Feed every message received
+// from WebSocket.OnMessage into it; other topics are ignored.
+func (as *AccountState) Handle(message map[string]interface{}) {
+	topic, _ := message["topic"].(string)
+	list, _ := message["data"].([]interface{})
+
+	var eventType string
+	switch {
+	case topic == "position" || strings.HasPrefix(topic, "position."):
+		eventType = AccountEventPosition
+	case topic == "order" || strings.HasPrefix(topic, "order."):
+		eventType = AccountEventOrder
+	case topic == "wallet":
+		eventType = AccountEventWallet
+	case topic == "execution" || strings.HasPrefix(topic, "execution."):
+		eventType = AccountEventExecution
+	default:
+		return
+	}
+
+	var events []AccountEvent
+
+	as.mu.Lock()
+	for _, raw := range list {
+		item, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch eventType {
+		case AccountEventPosition:
+			events = append(events, as.applyPosition(item)...)
+		case AccountEventOrder:
+			events = append(events, as.applyOrder(item)...)
+		case AccountEventWallet:
+			accountType, _ := item["accountType"].(string)
+			as.wallets[accountType] = item
+			as.walletsSeen[accountType] = time.Now().UnixMilli()
+			events = append(events, AccountEvent{Type: eventType, Key: accountType, Data: copyMap(item)})
+		case AccountEventExecution:
+			id, _ := item["execId"].(string)
+			events = append(events, AccountEvent{Type: eventType, Key: id, Data: copyMap(item)})
+		}
+	}
+	callbacks := as.callbacks
+	as.mu.Unlock()
+
+	for _, event := range events {
+		notify(callbacks, event)
+	}
+}
+
+func (as *AccountState) applyPosition(position map[string]interface{}) []AccountEvent {
+	key := positionKey(position)
+	if current, ok := as.positions[key]; ok && isStale(current, position) {
+		return nil
+	}
+
+	if isZeroSize(position["size"]) {
+		as.removedPositions[key] = newTombstone(position)
+		delete(as.positionsSeen, key)
+		if _, ok := as.positions[key]; !ok {
+			return nil
+		}
+		delete(as.positions, key)
+		return []AccountEvent{{Type: AccountEventPosition, Key: key, Data: copyMap(position), Removed: true}}
+	}
+
+	delete(as.removedPositions, key)
+	as.positions[key] = position
+	as.positionsSeen[key] = time.Now().UnixMilli()
+	return []AccountEvent{{Type: AccountEventPosition, Key: key, Data: copyMap(position)}}
+}
+
+func (as *AccountState) applyOrder(order map[string]interface{}) []AccountEvent {
+	key, _ := order["orderId"].(string)
+	if key == "" {
+		return nil
+	}
+	if current, ok := as.orders[key]; ok && isStale(current, order) {
+		return nil
+	}
+
+	status, _ := order["orderStatus"].(string)
+	if terminalOrderStatuses[status] {
+		as.removedOrders[key] = newTombstone(order)
+		delete(as.ordersSeen, key)
+		delete(as.orders, key)
+		return []AccountEvent{{Type: AccountEventOrder, Key: key, Data: copyMap(order), Removed: true}}
+	}
+
+	delete(as.removedOrders, key)
+	as.orders[key] = order
+	as.ordersSeen[key] = time.Now().UnixMilli()
+	return []AccountEvent{{Type: AccountEventOrder, Key: key, Data: copyMap(order)}}
+}
+
+// Positions returns all open positions.
+func (as *AccountState) Positions() []map[string]interface{} {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+	return copyItems(as.positions)
+}
+
+// Position returns the position of a symbol. positionIdx is 0 in one-way
+// mode, 1 or 2 for the long and short side in hedge mode.
+func (as *AccountState) Position(category, symbol string, positionIdx int) (map[string]interface{}, bool) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	position, ok := as.positions[category+":"+symbol+":"+strconv.Itoa(positionIdx)]
+	if !ok {
+		return nil, false
+	}
+	return copyMap(position), true
+}
+
+// OpenOrders returns open orders, optionally filtered by symbol.
+func (as *AccountState) OpenOrders(symbol string) []map[string]interface{} {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	orders := make([]map[string]interface{}, 0, len(as.orders))
+	for _, order := range as.orders {
+		if symbol == "" || order["symbol"] == symbol {
+			orders = append(orders, copyMap(order))
+		}
+	}
+	return orders
+}
+
+// Order returns an open order by orderId.
+func (as *AccountState) Order(orderID string) (map[string]interface{}, bool) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	order, ok := as.orders[orderID]
+	if !ok {
+		return nil, false
+	}
+	return copyMap(order), true
+}
+
+// Wallet returns the wallet of an account type, e.g. UNIFIED.
+func (as *AccountState) Wallet(accountType string) (map[string]interface{}, bool) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	wallet, ok := as.wallets[accountType]
+	if !ok {
+		return nil, false
+	}
+	return copyMap(wallet), true
+}
+
+func positionKey(position map[string]interface{}) string {
+	category, _ := position["category"].(string)
+	symbol, _ := position["symbol"].(string)
+	return category + ":" + symbol + ":" + strconv.FormatInt(toInt64(position["positionIdx"]), 10)
+}
+
+// isStale reports whether next is older than current by updatedTime.
+func isStale(current, next map[string]interface{}) bool {
+	return toInt64(next["updatedTime"]) < toInt64(current["updatedTime"])
+}
+
+func isZeroSize(v interface{}) bool {
+	size, _ := v.(string)
+	f, err := strconv.ParseFloat(size, 64)
+	return err == nil && f == 0
+}
+
+// mergeSnapshot replaces current with a REST snapshot requested at
+// started, keeping items the stream updated after the snapshot was
+// requested and dropping snapshot items the stream removed since. Removals
+// and updates applied before started are reflected by the snapshot and
+// forgotten.
+//
+// started and seen are local times, as the stream and the snapshot share
+// no server clock. Where both versions carry updatedTime, the newer one
+// wins by that server time; items without it, such as wallets, keep the
+// stream version when it arrived after started.
+func mergeSnapshot(current, snapshot map[string]map[string]interface{}, removed map[string]tombstone, seen map[string]int64, started int64) map[string]map[string]interface{} {
+	for key, fresh := range snapshot {
+		if t, ok := removed[key]; ok && toInt64(fresh["updatedTime"]) <= t.updated {
+			delete(snapshot, key)
+		}
+	}
+	for key, item := range current {
+		fresh, ok := snapshot[key]
+		streamed := seen[key] >= started
+		switch {
+		case !ok && streamed:
+			snapshot[key] = item
+		case ok && isStale(item, fresh):
+			snapshot[key] = item
+		case ok && streamed && toInt64(fresh["updatedTime"]) == 0:
+			snapshot[key] = item
+		}
+	}
+	for key, t := range removed {
+		if t.received < started {
+			delete(removed, key)
+		}
+	}
+	for key, at := range seen {
+		if at < started {
+			delete(seen, key)
+		}
+	}
+	return snapshot
+}
+
+func newTombstone(item map[string]interface{}) tombstone {
+	return tombstone{updated: toInt64(item["updatedTime"]), received: time.Now().UnixMilli()}
+}
+
+func notify(callbacks []func(AccountEvent), event AccountEvent) {
+	for _, callback := range callbacks {
+		callback(event)
+	}
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func copyItems(items map[string]map[string]interface{}) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		out = append(out, copyMap(item))
+	}
+	return out
+}
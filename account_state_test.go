@@ -0,0 +1,191 @@
+package bybit
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeAccountSource serves fixed REST snapshots. during runs while the
+// snapshot is in flight, between the positions and the wallet request, to
+// stand in for stream messages arriving meanwhile.
+type fakeAccountSource struct {
+	positions []map[string]interface{}
+	orders    []map[string]interface{}
+	wallets   []map[string]interface{}
+	during    func()
+}
+
+func listResponse(items []map[string]interface{}) map[string]interface{} {
+	list := make([]interface{}, len(items))
+	for i, item := range items {
+		list[i] = copyMap(item)
+	}
+	return map[string]interface{}{
+		"retCode": float64(0),
+		"result":  map[string]interface{}{"list": list},
+	}
+}
+
+func (s *fakeAccountSource) GetPositions(params map[string]interface{}) (map[string]interface{}, error) {
+	return listResponse(s.positions), nil
+}
+
+func (s *fakeAccountSource) GetOpenOrders(params map[string]interface{}) (map[string]interface{}, error) {
+	if s.during != nil {
+		s.during()
+	}
+	return listResponse(s.orders), nil
+}
+
+func (s *fakeAccountSource) GetWalletBalance(params map[string]interface{}) (map[string]interface{}, error) {
+	return listResponse(s.wallets), nil
+}
+
+func testPosition(symbol, size string, updated int64) map[string]interface{} {
+	return map[string]interface{}{
+		"category":    "linear",
+		"symbol":      symbol,
+		"positionIdx": float64(0),
+		"size":        size,
+		"updatedTime": strconv.FormatInt(updated, 10),
+	}
+}
+
+func privateMessage(topic string, items ...map[string]interface{}) map[string]interface{} {
+	data := make([]interface{}, len(items))
+	for i, item := range items {
+		data[i] = item
+	}
+	return map[string]interface{}{"topic": topic, "data": data}
+}
+
+func TestAccountStateReconcile(t *testing.T) {
+	// Server clocks run far behind the local one, so only comparisons
+	// between like clocks give the right answer.
+	const server = 1000
+
+	tests := []struct {
+		name     string
+		before   []map[string]interface{}
+		source   fakeAccountSource
+		during   []map[string]interface{}
+		position map[string]string
+		wallet   string
+	}{
+		{
+			name: "snapshot replaces older stream state",
+			before: []map[string]interface{}{
+				privateMessage("position", testPosition("BTCUSDT", "1", server)),
+				privateMessage("wallet", map[string]interface{}{"accountType": "UNIFIED", "totalEquity": "100"}),
+			},
+			source: fakeAccountSource{
+				positions: []map[string]interface{}{testPosition("BTCUSDT", "2", server+1)},
+				wallets:   []map[string]interface{}{{"accountType": "UNIFIED", "totalEquity": "200"}},
+			},
+			position: map[string]string{"BTCUSDT": "2"},
+			wallet:   "200",
+		},
+		{
+			name: "stream updates during the snapshot win",
+			source: fakeAccountSource{
+				positions: []map[string]interface{}{testPosition("BTCUSDT", "1", server)},
+				wallets:   []map[string]interface{}{{"accountType": "UNIFIED", "totalEquity": "100"}},
+			},
+			during: []map[string]interface{}{
+				privateMessage("position", testPosition("BTCUSDT", "3", server+1)),
+				privateMessage("wallet", map[string]interface{}{"accountType": "UNIFIED", "totalEquity": "300"}),
+			},
+			position: map[string]string{"BTCUSDT": "3"},
+			wallet:   "300",
+		},
+		{
+			name: "position opened during the snapshot is kept",
+			source: fakeAccountSource{
+				wallets: []map[string]interface{}{{"accountType": "UNIFIED", "totalEquity": "100"}},
+			},
+			during: []map[string]interface{}{
+				privateMessage("position", testPosition("ETHUSDT", "5", server)),
+			},
+			position: map[string]string{"ETHUSDT": "5"},
+			wallet:   "100",
+		},
+		{
+			name: "position closed during the snapshot stays closed",
+			source: fakeAccountSource{
+				positions: []map[string]interface{}{testPosition("BTCUSDT", "1", server)},
+				wallets:   []map[string]interface{}{{"accountType": "UNIFIED", "totalEquity": "100"}},
+			},
+			during: []map[string]interface{}{
+				privateMessage("position", testPosition("BTCUSDT", "0", server+1)),
+			},
+			position: map[string]string{},
+			wallet:   "100",
+		},
+		{
+			name: "position closed before the snapshot is dropped",
+			before: []map[string]interface{}{
+				privateMessage("position", testPosition("BTCUSDT", "1", server)),
+			},
+			source: fakeAccountSource{
+				wallets: []map[string]interface{}{{"accountType": "UNIFIED", "totalEquity": "100"}},
+			},
+			position: map[string]string{},
+			wallet:   "100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := tt.source
+			as := NewAccountState(&source, AccountStateConfig{ReconcileInterval: -1})
+			for _, msg := range tt.before {
+				as.Handle(msg)
+			}
+			// Keep stream updates apart from the snapshot request in local
+			// time, as they would be in practice.
+			time.Sleep(2 * time.Millisecond)
+			source.during = func() {
+				time.Sleep(2 * time.Millisecond)
+				for _, msg := range tt.during {
+					as.Handle(msg)
+				}
+			}
+
+			if err := as.Reconcile(); err != nil {
+				t.Fatal(err)
+			}
+
+			positions := as.Positions()
+			if len(positions) != len(tt.position) {
+				t.Fatalf("positions = %v, want %v", positions, tt.position)
+			}
+			for _, p := range positions {
+				if size := tt.position[p["symbol"].(string)]; p["size"] != size {
+					t.Errorf("%s size = %v, want %s", p["symbol"], p["size"], size)
+				}
+			}
+
+			wallet, ok := as.Wallet("UNIFIED")
+			if !ok || wallet["totalEquity"] != tt.wallet {
+				t.Errorf("wallet = %v, want totalEquity %s", wallet, tt.wallet)
+			}
+		})
+	}
+}
+
+func TestAccountStateStartTwice(t *testing.T) {
+	as := NewAccountState(&fakeAccountSource{}, AccountStateConfig{})
+	if err := as.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := as.Start(); err == nil {
+		t.Error("expected an error starting twice")
+	}
+
+	as.Stop()
+	if err := as.Start(); err != nil {
+		t.Errorf("restart after Stop: %v", err)
+	}
+	as.Stop()
+}
@@ -0,0 +1,351 @@
+package bybit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Periods accepted by open interest and long/short ratio queries.
+const (
+	Period5Min  = "5min"
+	Period15Min = "15min"
+	Period30Min = "30min"
+	Period1H    = "1h"
+	Period4H    = "4h"
+	Period1D    = "1d"
+)
+
+// SeriesQuery selects a page of a market time series. Period applies to
+// open interest and long/short ratio; Cursor continues from the
+// nextPageCursor of a previous page. Start, End, Limit and Cursor are
+// optional.
+type SeriesQuery struct {
+	Category string
+	Symbol   string
+	Period   string
+	Start    time.Time
+	End      time.Time
+	Limit    int
+	Cursor   string
+}
+
+func (q SeriesQuery) params(periodKey string) map[string]interface{} {
+	params := map[string]interface{}{
+		"category": q.Category,
+		"symbol":   q.Symbol,
+	}
+	if periodKey != "" && q.Period != "" {
+		params[periodKey] = q.Period
+	}
+	if !q.Start.IsZero() {
+		params["startTime"] = q.Start.UnixMilli()
+	}
+	if !q.End.IsZero() {
+		params["endTime"] = q.End.UnixMilli()
+	}
+	if q.Limit > 0 {
+		params["limit"] = q.Limit
+	}
+	if q.Cursor != "" {
+		params["cursor"] = q.Cursor
+	}
+	return params
+}
+
+// LongShortRatio is an item of account-ratio: the share of accounts
+// holding long and short positions.
+type LongShortRatio struct {
+	Symbol    string `json:"symbol"`
+	BuyRatio  string `json:"buyRatio"`
+	SellRatio string `json:"sellRatio"`
+	Timestamp string `json:"timestamp"`
+}
+
+// OpenInterestPoint is an item of open-interest.
+type OpenInterestPoint struct {
+	OpenInterest string `json:"openInterest"`
+	Timestamp    string `json:"timestamp"`
+}
+
+// FundingRate is an item of funding/history.
+type FundingRate struct {
+	Symbol               string `json:"symbol"`
+	FundingRate          string `json:"fundingRate"`
+	FundingRateTimestamp string `json:"fundingRateTimestamp"`
+}
+
+// DeliveryPrice is an item of delivery-price and new-delivery-price. The
+// latter leaves Symbol empty.
+type DeliveryPrice struct {
+	Symbol        string `json:"symbol"`
+	DeliveryPrice string `json:"deliveryPrice"`
+	DeliveryTime  string `json:"deliveryTime"`
+}
+
+// DeliveryQuery filters delivery-price. Category is required; the other
+// fields are optional.
+type DeliveryQuery struct {
+	Category   string
+	Symbol     string
+	BaseCoin   string
+	SettleCoin string
+	Limit      int
+	Cursor     string
+}
+
+// PriceLimitSnapshot is the result of price-limit: the order price band
+// currently enforced for a symbol.
+type PriceLimitSnapshot struct {
+	Symbol string `json:"symbol"`
+	// BuyLmt is the highest price a buy order may be placed at.
+	BuyLmt string `json:"buyLmt"`
+	// SellLmt is the lowest price a sell order may be placed at.
+	SellLmt string `json:"sellLmt"`
+	Ts      string `json:"ts"`
+}
+
+// LongShortRatios returns a page of the long/short account ratio of a
+// linear or inverse symbol, newest first, and the cursor of the next page.
+func (c *Client) LongShortRatios(q SeriesQuery) ([]LongShortRatio, string, error) {
+	res, err := c.GetLongShortRatio(q.params("period"))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var list []LongShortRatio
+	cursor, err := decodeList(res, &list)
+	return list, cursor, err
+}
+
+// OpenInterestHistory returns a page of open interest, newest first, and
+// the cursor of the next page.
+func (c *Client) OpenInterestHistory(q SeriesQuery) ([]OpenInterestPoint, string, error) {
+	res, err := c.GetOpenInterest(q.params("intervalTime"))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var list []OpenInterestPoint
+	cursor, err := decodeList(res, &list)
+	return list, cursor, err
+}
+
+// FundingRates returns a page of funding rate history, newest first.
+// Funding history has no cursor: page backwards by setting End below the
+// oldest item returned. Period and Cursor are ignored.
+func (c *Client) FundingRates(q SeriesQuery) ([]FundingRate, error) {
+	res, err := c.GetFundingRateHistory(q.params(""))
+	if err != nil {
+		return nil, err
+	}
+
+	var list []FundingRate
+	_, err = decodeList(res, &list)
+	return list, err
+}
+
+// DeliveryPrices returns a page of delivery prices of expired futures or
+// options and the cursor of the next page.
+func (c *Client) DeliveryPrices(q DeliveryQuery) ([]DeliveryPrice, string, error) {
+	params := map[string]interface{}{"category": q.Category}
+	if q.Symbol != "" {
+		params["symbol"] = q.Symbol
+	}
+	if q.BaseCoin != "" {
+		params["baseCoin"] = q.BaseCoin
+	}
+	if q.SettleCoin != "" {
+		params["settleCoin"] = q.SettleCoin
+	}
+	if q.Limit > 0 {
+		params["limit"] = q.Limit
+	}
+	if q.Cursor != "" {
+		params["cursor"] = q.Cursor
+	}
+
+	res, err := c.GetDeliveryPrice(params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var list []DeliveryPrice
+	cursor, err := decodeList(res, &list)
+	return list, cursor, err
+}
+
+// NewDeliveryPrices returns the most recent option delivery prices of a base
+// coin. settleCoin is optional.
+func (c *Client) NewDeliveryPrices(baseCoin, settleCoin string) ([]DeliveryPrice, error) {
+	params := map[string]interface{}{
+		"category": CategoryOption,
+		"baseCoin": baseCoin,
+	}
+	if settleCoin != "" {
+		params["settleCoin"] = settleCoin
+	}
+
+	res, err := c.GetNewDeliveryPrice(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []DeliveryPrice
+	_, err = decodeList(res, &list)
+	return list, err
+}
+
+// PriceLimits returns the order price band of a symbol.
+func (c *Client) PriceLimits(category, symbol string) (*PriceLimitSnapshot, error) {
+	res, err := c.GetPriceLimit(map[string]interface{}{
+		"category": category,
+		"symbol":   symbol,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var limit PriceLimitSnapshot
+	if err := decodeResult(res, &limit); err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+// DerivativesPoint is one step of a DerivativesSeries. Values are carried
+// forward from the latest observation at or before Time; each is zero until
+// its series has a first observation.
+type DerivativesPoint struct {
+	Time         time.Time
+	OpenInterest float64
+	BuyRatio     float64
+	SellRatio    float64
+	// FundingRate is the last settled rate, FundingTime its settlement time.
+	FundingRate float64
+	FundingTime time.Time
+}
+
+// DerivativesSeries joins open interest, long/short ratio and funding rate
+// of a linear or inverse symbol into one series, oldest first, with a point
+// for every open interest or ratio timestamp between from and to. period is
+// one of the Period constants.
+func (c *Client) DerivativesSeries(category, symbol, period string, from, to time.Time) ([]DerivativesPoint, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	points := make(map[int64]*DerivativesPoint)
+	point := func(ms int64) *DerivativesPoint {
+		p, ok := points[ms]
+		if !ok {
+			p = &DerivativesPoint{Time: time.UnixMilli(ms).UTC()}
+			points[ms] = p
+		}
+		return p
+	}
+
+	hasOI := make(map[int64]bool)
+	q := SeriesQuery{Category: category, Symbol: symbol, Period: period, Start: from, End: to, Limit: 200}
+	for {
+		page, cursor, err := c.OpenInterestHistory(q)
+		if err != nil {
+			return nil, fmt.Errorf("open interest: %w", err)
+		}
+		for _, item := range page {
+			ms := toInt64(item.Timestamp)
+			oi, err := strconv.ParseFloat(item.OpenInterest, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid open interest %q: %w", item.OpenInterest, err)
+			}
+			point(ms).OpenInterest = oi
+			hasOI[ms] = true
+		}
+		if cursor == "" || len(page) == 0 {
+			break
+		}
+		q.Cursor = cursor
+	}
+
+	hasRatio := make(map[int64]bool)
+	q = SeriesQuery{Category: category, Symbol: symbol, Period: period, Start: from, End: to, Limit: 500}
+	for {
+		page, cursor, err := c.LongShortRatios(q)
+		if err != nil {
+			return nil, fmt.Errorf("long/short ratio: %w", err)
+		}
+		for _, item := range page {
+			ms := toInt64(item.Timestamp)
+			buy, err := strconv.ParseFloat(item.BuyRatio, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid buy ratio %q: %w", item.BuyRatio, err)
+			}
+			sell, err := strconv.ParseFloat(item.SellRatio, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sell ratio %q: %w", item.SellRatio, err)
+			}
+			p := point(ms)
+			p.BuyRatio, p.SellRatio = buy, sell
+			hasRatio[ms] = true
+		}
+		if cursor == "" || len(page) == 0 {
+			break
+		}
+		q.Cursor = cursor
+	}
+
+	var funding []FundingRate
+	q = SeriesQuery{Category: category, Symbol: symbol, Start: from, End: to, Limit: 200}
+	for {
+		page, err := c.FundingRates(q)
+		if err != nil {
+			return nil, fmt.Errorf("funding rate: %w", err)
+		}
+		funding = append(funding, page...)
+		if len(page) < q.Limit {
+			break
+		}
+		oldest := toInt64(page[len(page)-1].FundingRateTimestamp)
+		if oldest <= from.UnixMilli() {
+			break
+		}
+		q.End = time.UnixMilli(oldest - 1)
+	}
+	sort.Slice(funding, func(i, j int) bool {
+		return toInt64(funding[i].FundingRateTimestamp) < toInt64(funding[j].FundingRateTimestamp)
+	})
+
+	keys := make([]int64, 0, len(points))
+	for ms := range points {
+		keys = append(keys, ms)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	series := make([]DerivativesPoint, 0, len(keys))
+	var last DerivativesPoint
+	next := 0
+	for _, ms := range keys {
+		p := points[ms]
+		if hasOI[ms] {
+			last.OpenInterest = p.OpenInterest
+		}
+		if hasRatio[ms] {
+			last.BuyRatio, last.SellRatio = p.BuyRatio, p.SellRatio
+		}
+		for next < len(funding) && toInt64(funding[next].FundingRateTimestamp) <= ms {
+			rate, err := strconv.ParseFloat(funding[next].FundingRate, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid funding rate %q: %w", funding[next].FundingRate, err)
+			}
+			last.FundingRate = rate
+			last.FundingTime = time.UnixMilli(toInt64(funding[next].FundingRateTimestamp)).UTC()
+			next++
+		}
+
+		last.Time = p.Time
+		series = append(series, last)
+	}
+
+	return series, nil
+}
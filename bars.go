@@ -0,0 +1,479 @@
+package bybit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bar types supported by BarBuilder.
+const (
+	BarTypeTime   = "time"
+	BarTypeTick   = "tick"
+	BarTypeVolume = "volume"
+	BarTypeDollar = "dollar"
+)
+
+// Trade is a single public trade.
+type Trade struct {
+	ID     string
+	Symbol string
+	Time   time.Time
+	// Side is the taker side: Buy or Sell.
+	Side  string
+	Price float64
+	Size  float64
+}
+
+// Bar is an aggregated OHLCV bar.
+type Bar struct {
+	Symbol     string
+	Start      time.Time
+	End        time.Time
+	Open       float64
+	High       float64
+	Low        float64
+	Close      float64
+	Volume     float64
+	Turnover   float64
+	VWAP       float64
+	BuyVolume  float64
+	SellVolume float64
+	Trades     int
+}
+
+// BarConfig configures a BarBuilder.
+type BarConfig struct {
+	// Type is BarTypeTime, BarTypeTick, BarTypeVolume or BarTypeDollar.
+	Type string
+	// Interval is the bar length of time bars, e.g. 5 * time.Second.
+	// Bars are aligned to multiples of the interval since the Unix epoch.
+	Interval time.Duration
+	// Threshold closes tick bars after this many trades, volume bars after
+	// this base quantity and dollar bars after this quote notional. The
+	// trade crossing the threshold is included whole, it is not split.
+	Threshold float64
+	// MaxHistory is the number of closed bars kept per symbol.
+	// Defaults to 1000.
+	MaxHistory int
+}
+
+// MarketDataSource provides the REST data used to seed a BarBuilder.
+// *Client implements it.
+type MarketDataSource interface {
+	GetRecentTrades(params map[string]interface{}) (map[string]interface{}, error)
+	GetKline(params map[string]interface{}) (map[string]interface{}, error)
+}
+
+// BarBuilder aggregates the publicTrade.{symbol} stream into time, tick,
+// volume or dollar bars. Feed messages from WebSocket.OnMessage into Handle,
+// or single trades into AddTrade. It is safe for concurrent use.
+type BarBuilder struct {
+	config  BarConfig
+	current map[string]*Bar
+	history map[string][]Bar
+	onBar   func(Bar)
+	mu      sync.Mutex
+}
+
+// NewBarBuilder creates a bar builder.
+func NewBarBuilder(config BarConfig) (*BarBuilder, error) {
+	switch config.Type {
+	case BarTypeTime:
+		if config.Interval <= 0 {
+			return nil, fmt.Errorf("time bars need a positive interval")
+		}
+	case BarTypeTick, BarTypeVolume, BarTypeDollar:
+		if config.Threshold <= 0 {
+			return nil, fmt.Errorf("%s bars need a positive threshold", config.Type)
+		}
+	default:
+		return nil, fmt.Errorf("unknown bar type %q", config.Type)
+	}
+	if config.MaxHistory <= 0 {
+		config.MaxHistory = 1000
+	}
+
+	return &BarBuilder{
+		config:  config,
+		current: make(map[string]*Bar),
+		history: make(map[string][]Bar),
+	}, nil
+}
+
+// OnBar registers a callback fired with every closed bar.
+func (b *BarBuilder) OnBar(callback func(Bar)) {
+	b.mu.Lock()
+	b.onBar = callback
+	b.mu.Unlock()
+}
+
+// Handle adds the trades of a publicTrade message. Other topics are ignored.
+func (b *BarBuilder) Handle(message map[string]interface{}) error {
+	topic, _ := message["topic"].(string)
+	if !strings.HasPrefix(topic, "publicTrade.") {
+		return nil
+	}
+
+	list, ok := message["data"].([]interface{})
+	if !ok {
+		return fmt.Errorf("invalid trade message for topic %s", topic)
+	}
+
+	for _, item := range list {
+		raw, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		trade, err := parseStreamTrade(raw)
+		if err != nil {
+			return err
+		}
+		b.AddTrade(trade)
+	}
+	return nil
+}
+
+// AddTrade adds a trade to the current bar of its symbol, closing bars as
+// needed. Trades must arrive in time order per symbol.
+func (b *BarBuilder) AddTrade(trade Trade) {
+	var closed []Bar
+
+	b.mu.Lock()
+	bar := b.current[trade.Symbol]
+
+	if b.config.Type == BarTypeTime {
+		start := alignToInterval(trade.Time, b.config.Interval)
+		if bar != nil && !trade.Time.Before(bar.End) {
+			closed = append(closed, b.closeBar(trade.Symbol))
+			bar = nil
+		}
+		if bar == nil {
+			bar = &Bar{Symbol: trade.Symbol, Start: start, End: start.Add(b.config.Interval)}
+			b.current[trade.Symbol] = bar
+		}
+	} else if bar == nil {
+		bar = &Bar{Symbol: trade.Symbol, Start: trade.Time}
+		b.current[trade.Symbol] = bar
+	}
+
+	addToBar(bar, trade)
+
+	if b.config.Type != BarTypeTime && b.thresholdReached(bar) {
+		bar.End = trade.Time
+		closed = append(closed, b.closeBar(trade.Symbol))
+	}
+	callback := b.onBar
+	b.mu.Unlock()
+
+	if callback != nil {
+		for _, c := range closed {
+			callback(c)
+		}
+	}
+}
+
+// Flush closes time bars whose interval ended before now even if no later
+// trade arrived. Call it periodically on quiet symbols.
+func (b *BarBuilder) Flush(now time.Time) {
+	if b.config.Type != BarTypeTime {
+		return
+	}
+
+	var closed []Bar
+
+	b.mu.Lock()
+	for symbol, bar := range b.current {
+		if !now.Before(bar.End) {
+			closed = append(closed, b.closeBar(symbol))
+		}
+	}
+	callback := b.onBar
+	b.mu.Unlock()
+
+	if callback != nil {
+		for _, c := range closed {
+			callback(c)
+		}
+	}
+}
+
+// Current returns the bar being built for a symbol.
+func (b *BarBuilder) Current(symbol string) (Bar, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bar, ok := b.current[symbol]
+	if !ok {
+		return Bar{}, false
+	}
+	return *bar, true
+}
+
+// History returns the closed bars of a symbol, oldest first.
+func (b *BarBuilder) History(symbol string) []Bar {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bars := make([]Bar, len(b.history[symbol]))
+	copy(bars, b.history[symbol])
+	return bars
+}
+
+// SeedFromTrades replays the latest trades from GetRecentTrades (up to
+// limit) through the builder. Call it before feeding the live stream.
+func (b *BarBuilder) SeedFromTrades(source MarketDataSource, category, symbol string, limit int) error {
+	params := map[string]interface{}{
+		"category": category,
+		"symbol":   symbol,
+	}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+
+	res, err := source.GetRecentTrades(params)
+	if err != nil {
+		return err
+	}
+	list, _, err := resultList(res)
+	if err != nil {
+		return err
+	}
+
+	// Recent trades are returned newest first.
+	for i := len(list) - 1; i >= 0; i-- {
+		raw, ok := list[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		trade, err := parseRESTTrade(raw)
+		if err != nil {
+			return err
+		}
+		b.AddTrade(trade)
+	}
+	return nil
+}
+
+// SeedFromKline loads closed bars from GetKline into the history of a
+// symbol. Only time bars can be seeded this way; interval is Bybit's kline
+// interval (1, 3, 5, ... D, W) and must match the bar interval. Kline
+// data has no buy/sell split or trade count, so BuyVolume, SellVolume and
+// Trades stay zero. The newest candle, if still forming, becomes the
+// current bar rather than a closed one.
+func (b *BarBuilder) SeedFromKline(source MarketDataSource, category, symbol, interval string, limit int) error {
+	if b.config.Type != BarTypeTime {
+		return fmt.Errorf("only time bars can be seeded from klines")
+	}
+	d, err := klineIntervalDuration(interval)
+	if err != nil {
+		return err
+	}
+	if d != b.config.Interval {
+		return fmt.Errorf("kline interval %s is %s, bar interval is %s", interval, d, b.config.Interval)
+	}
+
+	params := map[string]interface{}{
+		"category": category,
+		"symbol":   symbol,
+		"interval": interval,
+	}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+
+	res, err := source.GetKline(params)
+	if err != nil {
+		return err
+	}
+	list, _, err := resultList(res)
+	if err != nil {
+		return err
+	}
+
+	bars := make([]Bar, 0, len(list))
+	for i := len(list) - 1; i >= 0; i-- {
+		row, ok := list[i].([]interface{})
+		if !ok || len(row) < 7 {
+			continue
+		}
+		bar, err := klineRowToBar(symbol, row, b.config.Interval)
+		if err != nil {
+			return err
+		}
+		bars = append(bars, bar)
+	}
+
+	b.mu.Lock()
+	if len(bars) > 0 {
+		last := bars[len(bars)-1]
+		current, ok := b.current[symbol]
+		switch {
+		case ok && !last.Start.Before(current.Start):
+			// The candle overlaps the bar being built, which wins.
+			bars = bars[:len(bars)-1]
+		case !ok && time.Now().Before(last.End):
+			// Bybit's newest candle is still forming: keep building it
+			// from the live trades instead of storing it as closed.
+			bars = bars[:len(bars)-1]
+			b.current[symbol] = &last
+		}
+	}
+	b.history[symbol] = trimBars(append(bars, b.history[symbol]...), b.config.MaxHistory)
+	b.mu.Unlock()
+
+	return nil
+}
+
+// closeBar moves the current bar of a symbol into its history.
+// The caller must hold mu.
+func (b *BarBuilder) closeBar(symbol string) Bar {
+	bar := *b.current[symbol]
+	delete(b.current, symbol)
+	b.history[symbol] = trimBars(append(b.history[symbol], bar), b.config.MaxHistory)
+	return bar
+}
+
+func (b *BarBuilder) thresholdReached(bar *Bar) bool {
+	switch b.config.Type {
+	case BarTypeTick:
+		return float64(bar.Trades) >= b.config.Threshold
+	case BarTypeVolume:
+		return bar.Volume >= b.config.Threshold
+	case BarTypeDollar:
+		return bar.Turnover >= b.config.Threshold
+	}
+	return false
+}
+
+func addToBar(bar *Bar, trade Trade) {
+	// A bar seeded from a kline has volume but no trade count and keeps
+	// its open, high and low.
+	if bar.Trades == 0 && bar.Volume == 0 {
+		bar.Open = trade.Price
+		bar.High = trade.Price
+		bar.Low = trade.Price
+	}
+	if trade.Price > bar.High {
+		bar.High = trade.Price
+	}
+	if trade.Price < bar.Low {
+		bar.Low = trade.Price
+	}
+	bar.Close = trade.Price
+	bar.Volume += trade.Size
+	bar.Turnover += trade.Size * trade.Price
+	if bar.Volume > 0 {
+		bar.VWAP = bar.Turnover / bar.Volume
+	}
+	if trade.Side == "Buy" {
+		bar.BuyVolume += trade.Size
+	} else {
+		bar.SellVolume += trade.Size
+	}
+	bar.Trades++
+}
+
+// alignToInterval rounds t down to a multiple of d since the Unix epoch.
+func alignToInterval(t time.Time, d time.Duration) time.Time {
+	ns := t.UnixNano()
+	return time.Unix(0, ns-ns%int64(d))
+}
+
+func trimBars(bars []Bar, max int) []Bar {
+	if len(bars) > max {
+		return bars[len(bars)-max:]
+	}
+	return bars
+}
+
+// parseStreamTrade parses a publicTrade stream item.
+func parseStreamTrade(raw map[string]interface{}) (Trade, error) {
+	price, err := parseFloatField(raw, "p")
+	if err != nil {
+		return Trade{}, err
+	}
+	size, err := parseFloatField(raw, "v")
+	if err != nil {
+		return Trade{}, err
+	}
+
+	id, _ := raw["i"].(string)
+	symbol, _ := raw["s"].(string)
+	side, _ := raw["S"].(string)
+
+	return Trade{
+		ID:     id,
+		Symbol: symbol,
+		Time:   time.UnixMilli(toInt64(raw["T"])),
+		Side:   side,
+		Price:  price,
+		Size:   size,
+	}, nil
+}
+
+// parseRESTTrade parses an item of /v5/market/recent-trade.
+func parseRESTTrade(raw map[string]interface{}) (Trade, error) {
+	price, err := parseFloatField(raw, "price")
+	if err != nil {
+		return Trade{}, err
+	}
+	size, err := parseFloatField(raw, "size")
+	if err != nil {
+		return Trade{}, err
+	}
+
+	id, _ := raw["execId"].(string)
+	symbol, _ := raw["symbol"].(string)
+	side, _ := raw["side"].(string)
+
+	return Trade{
+		ID:     id,
+		Symbol: symbol,
+		Time:   time.UnixMilli(toInt64(raw["time"])),
+		Side:   side,
+		Price:  price,
+		Size:   size,
+	}, nil
+}
+
+// klineRowToBar converts [start, open, high, low, close, volume, turnover].
+func klineRowToBar(symbol string, row []interface{}, interval time.Duration) (Bar, error) {
+	values := make([]float64, 6)
+	for i := 1; i <= 6; i++ {
+		s, _ := row[i].(string)
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return Bar{}, fmt.Errorf("invalid kline value %q: %w", s, err)
+		}
+		values[i-1] = f
+	}
+
+	start := time.UnixMilli(toInt64(row[0]))
+	bar := Bar{
+		Symbol:   symbol,
+		Start:    start,
+		End:      start.Add(interval),
+		Open:     values[0],
+		High:     values[1],
+		Low:      values[2],
+		Close:    values[3],
+		Volume:   values[4],
+		Turnover: values[5],
+	}
+	if bar.Volume > 0 {
+		bar.VWAP = bar.Turnover / bar.Volume
+	}
+	return bar, nil
+}
+
+func parseFloatField(raw map[string]interface{}, key string) (float64, error) {
+	s, _ := raw[key].(string)
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid trade %s %q: %w", key, s, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,292 @@
+package bybit
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBarBuilderAddTrade(t *testing.T) {
+	base := time.UnixMilli(1700000000000)
+	at := func(ms int) time.Time { return base.Add(time.Duration(ms) * time.Millisecond) }
+
+	tests := []struct {
+		name    string
+		config  BarConfig
+		trades  []Trade
+		closed  []Bar
+		current *Bar
+	}{
+		{
+			name:   "time bars close on the first trade of the next interval",
+			config: BarConfig{Type: BarTypeTime, Interval: time.Second},
+			trades: []Trade{
+				{Time: at(100), Side: "Buy", Price: 10, Size: 1},
+				{Time: at(500), Side: "Sell", Price: 12, Size: 1},
+				{Time: at(900), Side: "Buy", Price: 9, Size: 2},
+				{Time: at(1000), Side: "Buy", Price: 11, Size: 1},
+			},
+			closed: []Bar{
+				{Start: at(0), End: at(1000), Open: 10, High: 12, Low: 9, Close: 9, Volume: 4, Turnover: 40, VWAP: 10, BuyVolume: 3, SellVolume: 1, Trades: 3},
+			},
+			current: &Bar{Start: at(1000), End: at(2000), Open: 11, High: 11, Low: 11, Close: 11, Volume: 1, Turnover: 11, VWAP: 11, BuyVolume: 1, Trades: 1},
+		},
+		{
+			name:   "time bars skip empty intervals",
+			config: BarConfig{Type: BarTypeTime, Interval: time.Second},
+			trades: []Trade{
+				{Time: at(100), Side: "Buy", Price: 10, Size: 1},
+				{Time: at(3200), Side: "Sell", Price: 11, Size: 1},
+			},
+			closed: []Bar{
+				{Start: at(0), End: at(1000), Open: 10, High: 10, Low: 10, Close: 10, Volume: 1, Turnover: 10, VWAP: 10, BuyVolume: 1, Trades: 1},
+			},
+			current: &Bar{Start: at(3000), End: at(4000), Open: 11, High: 11, Low: 11, Close: 11, Volume: 1, Turnover: 11, VWAP: 11, SellVolume: 1, Trades: 1},
+		},
+		{
+			name:   "tick bars",
+			config: BarConfig{Type: BarTypeTick, Threshold: 2},
+			trades: []Trade{
+				{Time: at(1), Side: "Buy", Price: 10, Size: 1},
+				{Time: at(2), Side: "Buy", Price: 11, Size: 1},
+				{Time: at(3), Side: "Sell", Price: 12, Size: 1},
+			},
+			closed: []Bar{
+				{Start: at(1), End: at(2), Open: 10, High: 11, Low: 10, Close: 11, Volume: 2, Turnover: 21, VWAP: 10.5, BuyVolume: 2, Trades: 2},
+			},
+			current: &Bar{Start: at(3), Open: 12, High: 12, Low: 12, Close: 12, Volume: 1, Turnover: 12, VWAP: 12, SellVolume: 1, Trades: 1},
+		},
+		{
+			name:   "volume bars include the crossing trade whole",
+			config: BarConfig{Type: BarTypeVolume, Threshold: 3},
+			trades: []Trade{
+				{Time: at(1), Side: "Buy", Price: 10, Size: 2},
+				{Time: at(2), Side: "Sell", Price: 10, Size: 2},
+			},
+			closed: []Bar{
+				{Start: at(1), End: at(2), Open: 10, High: 10, Low: 10, Close: 10, Volume: 4, Turnover: 40, VWAP: 10, BuyVolume: 2, SellVolume: 2, Trades: 2},
+			},
+		},
+		{
+			name:   "dollar bars",
+			config: BarConfig{Type: BarTypeDollar, Threshold: 100},
+			trades: []Trade{
+				{Time: at(1), Side: "Buy", Price: 50, Size: 1},
+				{Time: at(2), Side: "Buy", Price: 40, Size: 1},
+			},
+			current: &Bar{Start: at(1), Open: 50, High: 50, Low: 40, Close: 40, Volume: 2, Turnover: 90, VWAP: 45, BuyVolume: 2, Trades: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewBarBuilder(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var closed []Bar
+			b.OnBar(func(bar Bar) { closed = append(closed, bar) })
+
+			for _, trade := range tt.trades {
+				trade.Symbol = "BTCUSDT"
+				b.AddTrade(trade)
+			}
+
+			if len(closed) != len(tt.closed) {
+				t.Fatalf("closed %d bars, want %d: %+v", len(closed), len(tt.closed), closed)
+			}
+			for i, want := range tt.closed {
+				want.Symbol = "BTCUSDT"
+				assertBar(t, closed[i], want)
+			}
+			if got := b.History("BTCUSDT"); len(got) != len(tt.closed) {
+				t.Errorf("history has %d bars, want %d", len(got), len(tt.closed))
+			}
+
+			current, ok := b.Current("BTCUSDT")
+			if ok != (tt.current != nil) {
+				t.Fatalf("current bar present = %v, want %v", ok, tt.current != nil)
+			}
+			if ok {
+				want := *tt.current
+				want.Symbol = "BTCUSDT"
+				assertBar(t, current, want)
+			}
+		})
+	}
+}
+
+func TestNewBarBuilderValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config BarConfig
+	}{
+		{"time without interval", BarConfig{Type: BarTypeTime}},
+		{"tick without threshold", BarConfig{Type: BarTypeTick}},
+		{"unknown type", BarConfig{Type: "renko", Threshold: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewBarBuilder(tt.config); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// fakeKlines serves GetKline from rows given oldest first, returning them
+// newest first as Bybit does.
+type fakeKlines [][]interface{}
+
+func (f fakeKlines) GetRecentTrades(map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{"retCode": float64(0), "result": map[string]interface{}{"list": []interface{}{}}}, nil
+}
+
+func (f fakeKlines) GetKline(map[string]interface{}) (map[string]interface{}, error) {
+	list := make([]interface{}, len(f))
+	for i, row := range f {
+		list[len(f)-1-i] = row
+	}
+	return map[string]interface{}{"retCode": float64(0), "result": map[string]interface{}{"list": list}}, nil
+}
+
+func klineRow(start time.Time, open, high, low, close, volume float64) []interface{} {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return []interface{}{
+		strconv.FormatInt(start.UnixMilli(), 10),
+		f(open), f(high), f(low), f(close), f(volume), f(volume * close),
+	}
+}
+
+func TestBarBuilderSeedFromKline(t *testing.T) {
+	// An hour-long interval keeps the forming candle forming for the
+	// duration of the test.
+	interval := time.Hour
+	now := alignToInterval(time.Now(), interval)
+
+	tests := []struct {
+		name        string
+		rows        fakeKlines
+		liveTrade   bool
+		history     int
+		current     bool
+		currentOpen float64
+	}{
+		{
+			name: "closed candles only",
+			rows: fakeKlines{
+				klineRow(now.Add(-3*interval), 10, 11, 9, 10, 1),
+				klineRow(now.Add(-2*interval), 10, 12, 10, 11, 1),
+			},
+			history: 2,
+		},
+		{
+			name: "forming candle becomes the current bar",
+			rows: fakeKlines{
+				klineRow(now.Add(-2*interval), 10, 11, 9, 10, 1),
+				klineRow(now.Add(-interval), 10, 12, 10, 11, 1),
+				klineRow(now, 11, 13, 11, 12, 2),
+			},
+			history:     2,
+			current:     true,
+			currentOpen: 11,
+		},
+		{
+			name: "live bar wins over the overlapping candle",
+			rows: fakeKlines{
+				klineRow(now.Add(-interval), 10, 12, 10, 11, 1),
+				klineRow(now, 11, 13, 11, 12, 2),
+			},
+			liveTrade:   true,
+			history:     1,
+			current:     true,
+			currentOpen: 20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewBarBuilder(BarConfig{Type: BarTypeTime, Interval: interval})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.liveTrade {
+				b.AddTrade(Trade{Symbol: "BTCUSDT", Time: time.Now(), Side: "Buy", Price: 20, Size: 1})
+			}
+
+			if err := b.SeedFromKline(tt.rows, CategoryLinear, "BTCUSDT", "60", 0); err != nil {
+				t.Fatal(err)
+			}
+
+			history := b.History("BTCUSDT")
+			if len(history) != tt.history {
+				t.Fatalf("history has %d bars, want %d", len(history), tt.history)
+			}
+			for i := 1; i < len(history); i++ {
+				if !history[i].Start.After(history[i-1].Start) {
+					t.Errorf("history not in time order at %d", i)
+				}
+			}
+
+			current, ok := b.Current("BTCUSDT")
+			if ok != tt.current {
+				t.Fatalf("current bar present = %v, want %v", ok, tt.current)
+			}
+			if ok && current.Open != tt.currentOpen {
+				t.Errorf("current open = %v, want %v", current.Open, tt.currentOpen)
+			}
+		})
+	}
+}
+
+func TestSeededBarKeepsKlineOHLC(t *testing.T) {
+	interval := time.Hour
+	now := alignToInterval(time.Now(), interval)
+
+	b, err := NewBarBuilder(BarConfig{Type: BarTypeTime, Interval: interval})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := fakeKlines{klineRow(now, 100, 110, 90, 105, 3)}
+	if err := b.SeedFromKline(rows, CategoryLinear, "BTCUSDT", "60", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	b.AddTrade(Trade{Symbol: "BTCUSDT", Time: now.Add(time.Second), Side: "Sell", Price: 95, Size: 1})
+
+	bar, ok := b.Current("BTCUSDT")
+	if !ok {
+		t.Fatal("no current bar")
+	}
+	if bar.Open != 100 || bar.High != 110 || bar.Low != 90 || bar.Close != 95 || bar.Volume != 4 {
+		t.Errorf("bar = %+v, want open 100, high 110, low 90, close 95, volume 4", bar)
+	}
+}
+
+func TestSeedFromKlineIntervalMismatch(t *testing.T) {
+	b, err := NewBarBuilder(BarConfig{Type: BarTypeTime, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := fakeKlines{klineRow(alignToInterval(time.Now(), time.Hour), 100, 110, 90, 105, 3)}
+
+	for _, interval := range []string{"15", "D", "M", "1h"} {
+		if err := b.SeedFromKline(rows, CategoryLinear, "BTCUSDT", interval, 0); err == nil {
+			t.Errorf("interval %s: expected an error", interval)
+		}
+	}
+	if _, ok := b.Current("BTCUSDT"); ok {
+		t.Error("mismatched klines were seeded")
+	}
+}
+
+func assertBar(t *testing.T, got, want Bar) {
+	t.Helper()
+	if !got.Start.Equal(want.Start) || !got.End.Equal(want.End) {
+		t.Errorf("bar span = %v..%v, want %v..%v", got.Start, got.End, want.Start, want.End)
+	}
+	got.Start, got.End, want.Start, want.End = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+	if got != want {
+		t.Errorf("bar = %+v, want %+v", got, want)
+	}
+}
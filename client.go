@@ -21,6 +21,14 @@ import (
 	"time"
 )
 
+// Product categories used by the V5 API.
+const (
+	CategorySpot    = "spot"
+	CategoryLinear  = "linear"
+	CategoryInverse = "inverse"
+	CategoryOption  = "option"
+)
+
 type Client struct {
 	apiKey        string
 	apiSecret     string
@@ -298,6 +306,22 @@ func (c *Client) GetKline(params map[string]interface{}) (map[string]interface{}
 	return c.Request("GET", "/v5/market/kline", params)
 }
 
+func (c *Client) GetMarkPriceKline(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/market/mark-price-kline", params)
+}
+
+func (c *Client) GetIndexPriceKline(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/market/index-price-kline", params)
+}
+
+func (c *Client) GetPremiumIndexPriceKline(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/market/premium-index-price-kline", params)
+}
+
+func (c *Client) GetInstrumentsInfo(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/market/instruments-info", params)
+}
+
 func (c *Client) GetOrderbook(params map[string]interface{}) (map[string]interface{}, error) {
 	return c.Request("GET", "/v5/market/orderbook", params)
 }
@@ -310,6 +334,22 @@ func (c *Client) GetOpenInterest(params map[string]interface{}) (map[string]inte
 	return c.Request("GET", "/v5/market/open-interest", params)
 }
 
+func (c *Client) GetLongShortRatio(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/market/account-ratio", params)
+}
+
+func (c *Client) GetDeliveryPrice(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/market/delivery-price", params)
+}
+
+func (c *Client) GetNewDeliveryPrice(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/market/new-delivery-price", params)
+}
+
+func (c *Client) GetPriceLimit(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/market/price-limit", params)
+}
+
 func (c *Client) GetRecentTrades(params map[string]interface{}) (map[string]interface{}, error) {
 	return c.Request("GET", "/v5/market/recent-trade", params)
 }
@@ -354,6 +394,14 @@ func (c *Client) GetHistoryOrders(params map[string]interface{}) (map[string]int
 	return c.Request("GET", "/v5/order/history", params)
 }
 
+func (c *Client) GetTradeHistory(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/execution/list", params)
+}
+
+func (c *Client) PreCheckOrder(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/order/pre-check", params)
+}
+
 func (c *Client) GetWalletBalance(params map[string]interface{}) (map[string]interface{}, error) {
 	return c.Request("GET", "/v5/account/wallet-balance", params)
 }
@@ -362,6 +410,134 @@ func (c *Client) GetTransferableAmount(params map[string]interface{}) (map[strin
 	return c.Request("GET", "/v5/account/transferable-amount", params)
 }
 
+func (c *Client) CreateInternalTransfer(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/asset/transfer/inter-transfer", params)
+}
+
+func (c *Client) GetInternalTransferRecords(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/transfer/query-inter-transfer-list", params)
+}
+
+func (c *Client) CreateUniversalTransfer(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/asset/transfer/universal-transfer", params)
+}
+
+func (c *Client) GetUniversalTransferRecords(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/transfer/query-universal-transfer-list", params)
+}
+
+func (c *Client) GetAllCoinsBalance(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/transfer/query-account-coins-balance", params)
+}
+
+func (c *Client) GetSingleCoinBalance(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/transfer/query-account-coin-balance", params)
+}
+
+func (c *Client) GetDepositAddress(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/deposit/query-address", params)
+}
+
+func (c *Client) GetSubDepositAddress(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/deposit/query-sub-member-address", params)
+}
+
+func (c *Client) GetDepositRecords(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/deposit/query-record", params)
+}
+
+func (c *Client) GetSubDepositRecords(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/deposit/query-sub-member-record", params)
+}
+
+func (c *Client) GetInternalDepositRecords(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/deposit/query-internal-record", params)
+}
+
+func (c *Client) GetCoinInfo(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/coin/query-info", params)
+}
+
+func (c *Client) GetWithdrawableAmount(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/withdraw/withdrawable-amount", params)
+}
+
+func (c *Client) CreateWithdrawal(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/asset/withdraw/create", params)
+}
+
+func (c *Client) CancelWithdrawal(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/asset/withdraw/cancel", params)
+}
+
+func (c *Client) GetWithdrawalRecords(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/withdraw/query-record", params)
+}
+
+func (c *Client) GetConvertCoinList(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/exchange/query-coin-list", params)
+}
+
+func (c *Client) RequestConvertQuote(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/asset/exchange/quote-apply", params)
+}
+
+func (c *Client) ConfirmConvertQuote(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/asset/exchange/convert-execute", params)
+}
+
+func (c *Client) GetConvertStatus(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/exchange/convert-result-query", params)
+}
+
+func (c *Client) GetConvertHistory(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/asset/exchange/query-convert-history", params)
+}
+
+func (c *Client) CreateSubMember(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/user/create-sub-member", params)
+}
+
+func (c *Client) GetSubMembers() (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/user/query-sub-members", nil)
+}
+
+func (c *Client) GetSubMembersPaged(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/user/submembers", params)
+}
+
+func (c *Client) FreezeSubMember(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/user/frozen-sub-member", params)
+}
+
+func (c *Client) CreateSubAPIKey(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/user/create-sub-api", params)
+}
+
+func (c *Client) UpdateMasterAPIKey(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/user/update-api", params)
+}
+
+func (c *Client) UpdateSubAPIKey(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/user/update-sub-api", params)
+}
+
+func (c *Client) DeleteMasterAPIKey() (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/user/delete-api", nil)
+}
+
+func (c *Client) DeleteSubAPIKey(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/user/delete-sub-api", params)
+}
+
+func (c *Client) GetAPIKeyInfo() (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/user/query-api", nil)
+}
+
+func (c *Client) GetSubAPIKeys(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/user/sub-apikeys", params)
+}
+
 func (c *Client) GetTransactionLog(params map[string]interface{}) (map[string]interface{}, error) {
 	return c.Request("GET", "/v5/account/transaction-log", params)
 }
@@ -374,6 +550,50 @@ func (c *Client) GetAccountInstrumentsInfo(params map[string]interface{}) (map[s
 	return c.Request("GET", "/v5/account/instruments", params)
 }
 
+func (c *Client) GetFeeRate(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/account/fee-rate", params)
+}
+
+func (c *Client) SetMarginMode(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/account/set-margin-mode", params)
+}
+
+func (c *Client) SetSpotHedging(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/account/set-hedging-mode", params)
+}
+
+func (c *Client) SetCollateralCoin(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/account/set-collateral-switch", params)
+}
+
+func (c *Client) BatchSetCollateralCoin(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/account/set-collateral-switch-batch", params)
+}
+
+func (c *Client) GetCollateralInfo(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/account/collateral-info", params)
+}
+
+func (c *Client) GetBorrowHistory(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/account/borrow-history", params)
+}
+
+func (c *Client) UpgradeToUTA() (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/account/upgrade-to-uta", nil)
+}
+
+func (c *Client) SetMMP(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/account/mmp-modify", params)
+}
+
+func (c *Client) ResetMMP(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/account/mmp-reset", params)
+}
+
+func (c *Client) GetMMPState(params map[string]interface{}) (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/account/mmp-state", params)
+}
+
 func (c *Client) GetPositions(params map[string]interface{}) (map[string]interface{}, error) {
 	return c.Request("GET", "/v5/position/list", params)
 }
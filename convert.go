@@ -0,0 +1,324 @@
+package bybit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Convert wallets, as in ConvertRequest.AccountType.
+const (
+	ConvertAccountFunding  = "eb_convert_funding"
+	ConvertAccountUnified  = "eb_convert_uta"
+	ConvertAccountSpot     = "eb_convert_spot"
+	ConvertAccountContract = "eb_convert_contract"
+	ConvertAccountInverse  = "eb_convert_inverse"
+)
+
+// Convert statuses.
+const (
+	ConvertStatusInit       = "init"
+	ConvertStatusProcessing = "processing"
+	ConvertStatusSuccess    = "success"
+	ConvertStatusFailure    = "failure"
+)
+
+// Coin list sides of ConvertCoins.
+const (
+	ConvertSideFrom = 0
+	ConvertSideTo   = 1
+)
+
+// ConvertCodeQuoteExpired is the retCode of a confirm rejected because the
+// quote had expired. Only this rejection is known not to have converted
+// anything, so it is the only one ConvertWith retries with a new quote.
+const ConvertCodeQuoteExpired = 700003
+
+// quoteExpiryMargin is how close to expiry a quote may be before it is
+// requested again rather than confirmed.
+const quoteExpiryMargin = 500 * time.Millisecond
+
+// ConvertCoin is an item of the convertible coin list.
+type ConvertCoin struct {
+	Coin               string `json:"coin"`
+	FullName           string `json:"fullName"`
+	AccuracyLength     int    `json:"accuracyLength"`
+	CoinType           string `json:"coinType"`
+	Balance            string `json:"balance"`
+	UBalance           string `json:"uBalance"`
+	SingleFromMinLimit string `json:"singleFromMinLimit"`
+	SingleFromMaxLimit string `json:"singleFromMaxLimit"`
+	SingleToMinLimit   string `json:"singleToMinLimit"`
+	SingleToMaxLimit   string `json:"singleToMaxLimit"`
+	DailyFromMinLimit  string `json:"dailyFromMinLimit"`
+	DailyFromMaxLimit  string `json:"dailyFromMaxLimit"`
+	DailyToMinLimit    string `json:"dailyToMinLimit"`
+	DailyToMaxLimit    string `json:"dailyToMaxLimit"`
+	DisableFrom        bool   `json:"disableFrom"`
+	DisableTo          bool   `json:"disableTo"`
+	DisableUser        bool   `json:"disableUser"`
+}
+
+// ConvertQuote is the result of quote-apply.
+type ConvertQuote struct {
+	QuoteTxID    string `json:"quoteTxId"`
+	ExchangeRate string `json:"exchangeRate"`
+	FromCoin     string `json:"fromCoin"`
+	FromCoinType string `json:"fromCoinType"`
+	ToCoin       string `json:"toCoin"`
+	ToCoinType   string `json:"toCoinType"`
+	FromAmount   string `json:"fromAmount"`
+	ToAmount     string `json:"toAmount"`
+	ExpiredTime  string `json:"expiredTime"`
+	RequestID    string `json:"requestId"`
+}
+
+// ExpiresAt returns the time after which the quote can no longer be
+// confirmed.
+func (q *ConvertQuote) ExpiresAt() time.Time {
+	return time.UnixMilli(toInt64(q.ExpiredTime))
+}
+
+// ConvertRecord is a conversion, as returned by the status and history
+// queries.
+type ConvertRecord struct {
+	AccountType    string `json:"accountType"`
+	ExchangeTxID   string `json:"exchangeTxId"`
+	UserID         string `json:"userId"`
+	FromCoin       string `json:"fromCoin"`
+	FromCoinType   string `json:"fromCoinType"`
+	FromAmount     string `json:"fromAmount"`
+	ToCoin         string `json:"toCoin"`
+	ToCoinType     string `json:"toCoinType"`
+	ToAmount       string `json:"toAmount"`
+	ExchangeStatus string `json:"exchangeStatus"`
+	ConvertRate    string `json:"convertRate"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+// ConvertCoins returns the coins that can be converted from (side
+// ConvertSideFrom) or to (ConvertSideTo) in a wallet. coin is optional and
+// for side ConvertSideTo lists the coins coin converts into.
+func (c *Client) ConvertCoins(accountType string, side int, coin string) ([]ConvertCoin, error) {
+	params := map[string]interface{}{
+		"accountType": accountType,
+		"side":        side,
+	}
+	if coin != "" {
+		params["coin"] = coin
+	}
+
+	res, err := c.GetConvertCoinList(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Coins []ConvertCoin `json:"coins"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return nil, err
+	}
+	return result.Coins, nil
+}
+
+// ConvertQuoteFor requests a quote to convert amount of from into to. The
+// quote must be confirmed with ConfirmConvert before it expires.
+func (c *Client) ConvertQuoteFor(accountType, from, to, amount string) (*ConvertQuote, error) {
+	res, err := c.RequestConvertQuote(map[string]interface{}{
+		"fromCoin":      from,
+		"toCoin":        to,
+		"requestCoin":   from,
+		"requestAmount": amount,
+		"accountType":   accountType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var quote ConvertQuote
+	if err := decodeResult(res, &quote); err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// ConfirmConvert executes a quote and returns the initial convert status.
+func (c *Client) ConfirmConvert(quoteTxID string) (string, error) {
+	res, err := c.ConfirmConvertQuote(map[string]interface{}{"quoteTxId": quoteTxID})
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		ExchangeStatus string `json:"exchangeStatus"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return "", err
+	}
+	return result.ExchangeStatus, nil
+}
+
+// ConvertStatus returns the state of a confirmed quote.
+func (c *Client) ConvertStatus(accountType, quoteTxID string) (*ConvertRecord, error) {
+	res, err := c.GetConvertStatus(map[string]interface{}{
+		"quoteTxId":   quoteTxID,
+		"accountType": accountType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Result ConvertRecord `json:"result"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return nil, err
+	}
+	return &result.Result, nil
+}
+
+// ConvertHistory returns a page of conversions, newest first. page starts
+// at 1; accountTypes optionally restricts the wallets.
+func (c *Client) ConvertHistory(page, limit int, accountTypes ...string) ([]ConvertRecord, error) {
+	params := map[string]interface{}{}
+	if page > 0 {
+		params["index"] = page
+	}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+	if len(accountTypes) > 0 {
+		params["accountType"] = strings.Join(accountTypes, ",")
+	}
+
+	res, err := c.GetConvertHistory(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []ConvertRecord
+	_, err = decodeList(res, &list)
+	return list, err
+}
+
+// ConvertRequest describes a conversion run by ConvertWith.
+type ConvertRequest struct {
+	From   string
+	To     string
+	Amount string
+	// AccountType is the wallet to convert in. Defaults to
+	// ConvertAccountUnified.
+	AccountType string
+	// MaxAttempts bounds how many quotes are requested when quotes expire
+	// before confirmation. Defaults to 3.
+	MaxAttempts int
+	// Timeout bounds how long a confirmed conversion is polled. Defaults
+	// to 30s.
+	Timeout time.Duration
+}
+
+// Convert converts amount of from into to in the unified wallet and waits
+// for the result.
+func (c *Client) Convert(from, to, amount string) (*ConvertRecord, error) {
+	return c.ConvertWith(ConvertRequest{From: from, To: to, Amount: amount})
+}
+
+// ConvertWith requests a quote, confirms it and polls until the conversion
+// succeeds or fails. A quote about to expire, or rejected as expired by
+// the confirmation, is requested again up to MaxAttempts times. When the
+// confirmation fails otherwise, e.g. on a timeout, the outcome is unknown:
+// the quote is then polled if Bybit knows it, and never requested again,
+// so the balance is not converted twice.
+func (c *Client) ConvertWith(req ConvertRequest) (*ConvertRecord, error) {
+	if req.AccountType == "" {
+		req.AccountType = ConvertAccountUnified
+	}
+	if req.MaxAttempts == 0 {
+		req.MaxAttempts = 3
+	}
+	if req.Timeout == 0 {
+		req.Timeout = 30 * time.Second
+	}
+
+	var quote *ConvertQuote
+	var lastErr error
+	for attempt := 0; attempt < req.MaxAttempts; attempt++ {
+		q, err := c.ConvertQuoteFor(req.AccountType, req.From, req.To, req.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("convert quote: %w", err)
+		}
+		if time.Until(q.ExpiresAt()) < quoteExpiryMargin {
+			lastErr = fmt.Errorf("quote %s expired before confirmation", q.QuoteTxID)
+			continue
+		}
+
+		status, err := c.ConfirmConvert(q.QuoteTxID)
+		if err != nil {
+			if c.convertStarted(req.AccountType, q.QuoteTxID) {
+				quote = q
+				break
+			}
+			if isQuoteExpired(err) {
+				lastErr = fmt.Errorf("confirm quote %s: %w", q.QuoteTxID, err)
+				continue
+			}
+			return nil, fmt.Errorf("confirm quote %s: %w", q.QuoteTxID, err)
+		}
+		if status == ConvertStatusFailure {
+			return nil, fmt.Errorf("convert %s failed", q.QuoteTxID)
+		}
+
+		quote = q
+		break
+	}
+	if quote == nil {
+		return nil, fmt.Errorf("convert %s to %s: %w", req.From, req.To, lastErr)
+	}
+
+	deadline := time.Now().Add(req.Timeout)
+	delay := 250 * time.Millisecond
+	for {
+		record, err := c.ConvertStatus(req.AccountType, quote.QuoteTxID)
+		if err == nil {
+			switch record.ExchangeStatus {
+			case ConvertStatusSuccess:
+				return record, nil
+			case ConvertStatusFailure:
+				return record, fmt.Errorf("convert %s failed", quote.QuoteTxID)
+			}
+		}
+
+		if time.Now().After(deadline) {
+			if err != nil {
+				return nil, fmt.Errorf("convert %s status: %w", quote.QuoteTxID, err)
+			}
+			return record, fmt.Errorf("convert %s still %s after %s", quote.QuoteTxID, record.ExchangeStatus, req.Timeout)
+		}
+
+		time.Sleep(delay)
+		if delay < 2*time.Second {
+			delay *= 2
+		}
+	}
+}
+
+// convertStarted reports whether Bybit has a conversion for a quote whose
+// confirmation returned an error, i.e. the confirmation went through.
+func (c *Client) convertStarted(accountType, quoteTxID string) bool {
+	record, err := c.ConvertStatus(accountType, quoteTxID)
+	if err != nil {
+		return false
+	}
+	switch record.ExchangeStatus {
+	case ConvertStatusInit, ConvertStatusProcessing, ConvertStatusSuccess:
+		return true
+	}
+	return false
+}
+
+func isQuoteExpired(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == ConvertCodeQuoteExpired
+}
@@ -0,0 +1,159 @@
+package bybit
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestConvertWithRetries(t *testing.T) {
+	const (
+		confirmed = `{"retCode":0,"retMsg":"ok","result":{"exchangeStatus":"processing"}}`
+		rejected  = `{"retCode":0,"retMsg":"ok","result":{"exchangeStatus":"failure"}}`
+		timedOut  = `<html>504 Gateway Time-out</html>`
+		notFound  = `{"retCode":700001,"retMsg":"quote not found","result":{}}`
+		success   = "success"
+	)
+	expired := fmt.Sprintf(`{"retCode":%d,"retMsg":"quote expired","result":{}}`, ConvertCodeQuoteExpired)
+
+	tests := []struct {
+		name string
+		// expiries is the time left on each quote handed out; quotes
+		// beyond the list get 10s.
+		expiries []time.Duration
+		// confirms are the convert-execute responses, in order.
+		confirms []string
+		// statuses are the exchange statuses per quote, in order; the last
+		// one repeats and an empty one answers notFound.
+		statuses map[string][]string
+		quotes   int
+		confirm  int
+		wantErr  bool
+	}{
+		{
+			name:     "confirmed first time",
+			confirms: []string{confirmed},
+			statuses: map[string][]string{"q1": {success}},
+			quotes:   1,
+			confirm:  1,
+		},
+		{
+			name:     "quote about to expire is requested again",
+			expiries: []time.Duration{100 * time.Millisecond},
+			confirms: []string{confirmed},
+			statuses: map[string][]string{"q2": {success}},
+			quotes:   2,
+			confirm:  1,
+		},
+		{
+			name:     "confirm rejected as expired is retried",
+			confirms: []string{expired, confirmed},
+			statuses: map[string][]string{"q1": {""}, "q2": {success}},
+			quotes:   2,
+			confirm:  2,
+		},
+		{
+			name:     "failed confirm that went through is polled, not requoted",
+			confirms: []string{timedOut},
+			statuses: map[string][]string{"q1": {"processing", "processing", success}},
+			quotes:   1,
+			confirm:  1,
+		},
+		{
+			name:     "failed confirm with unknown outcome is not retried",
+			confirms: []string{timedOut},
+			statuses: map[string][]string{"q1": {""}},
+			quotes:   1,
+			confirm:  1,
+			wantErr:  true,
+		},
+		{
+			name:     "other api errors are not retried",
+			confirms: []string{`{"retCode":700005,"retMsg":"insufficient balance","result":{}}`},
+			statuses: map[string][]string{"q1": {""}},
+			quotes:   1,
+			confirm:  1,
+			wantErr:  true,
+		},
+		{
+			name:     "rejected conversion",
+			confirms: []string{rejected},
+			quotes:   1,
+			confirm:  1,
+			wantErr:  true,
+		},
+		{
+			name:     "every quote expires",
+			confirms: []string{expired, expired, expired},
+			statuses: map[string][]string{},
+			quotes:   3,
+			confirm:  3,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var quotes, confirms int
+			polls := make(map[string]int)
+
+			client, err := NewClient(ClientConfig{
+				APIKey:    "key",
+				APISecret: "secret",
+				HTTPClient: fakeHTTPClient(func(req *http.Request) string {
+					switch req.URL.Path {
+					case "/v5/asset/exchange/quote-apply":
+						ttl := 10 * time.Second
+						if quotes < len(tt.expiries) {
+							ttl = tt.expiries[quotes]
+						}
+						quotes++
+						return fmt.Sprintf(`{"retCode":0,"retMsg":"ok","result":{"quoteTxId":"q%d","expiredTime":"%d"}}`,
+							quotes, time.Now().Add(ttl).UnixMilli())
+					case "/v5/asset/exchange/convert-execute":
+						confirms++
+						if confirms > len(tt.confirms) {
+							t.Fatalf("unexpected confirm %d", confirms)
+						}
+						return tt.confirms[confirms-1]
+					case "/v5/asset/exchange/convert-result-query":
+						id := req.URL.Query().Get("quoteTxId")
+						statuses := tt.statuses[id]
+						if len(statuses) == 0 {
+							return notFound
+						}
+						i := polls[id]
+						if i >= len(statuses) {
+							i = len(statuses) - 1
+						}
+						polls[id]++
+						if statuses[i] == "" {
+							return notFound
+						}
+						return fmt.Sprintf(`{"retCode":0,"retMsg":"ok","result":{"result":{"exchangeStatus":"%s","quoteTxId":"%s"}}}`, statuses[i], id)
+					}
+					t.Fatalf("unexpected request %s", req.URL.Path)
+					return ""
+				}),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			record, err := client.ConvertWith(ConvertRequest{From: "USDT", To: "BTC", Amount: "10", Timeout: 5 * time.Second})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && record.ExchangeStatus != ConvertStatusSuccess {
+				t.Errorf("status = %s, want %s", record.ExchangeStatus, ConvertStatusSuccess)
+			}
+			if quotes != tt.quotes {
+				t.Errorf("quotes = %d, want %d", quotes, tt.quotes)
+			}
+			if confirms != tt.confirm {
+				t.Errorf("confirms = %d, want %d", confirms, tt.confirm)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package bybit
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dcpRESTProducts maps the dcp.{product} topic names to the product names
+// of the REST endpoint.
+var dcpRESTProducts = map[string]string{
+	DCPProductFutures: "DERIVATIVES",
+	DCPProductSpot:    "SPOT",
+	DCPProductOptions: "OPTIONS",
+}
+
+// SetDCP sets the Disconnection Protect time window in seconds (3–300) for
+// a product: DCPProductFutures, DCPProductSpot or DCPProductOptions. Once
+// set, Bybit cancels all orders of the product when every private
+// connection subscribed to dcp.{product} has been down for the window.
+func (c *Client) SetDCP(product string, timeWindow int) (map[string]interface{}, error) {
+	restProduct, ok := dcpRESTProducts[strings.ToLower(product)]
+	if !ok {
+		restProduct = strings.ToUpper(product)
+	}
+
+	return c.Request("POST", "/v5/order/disconnected-cancel-all", map[string]interface{}{
+		"product":    restProduct,
+		"timeWindow": timeWindow,
+	})
+}
+
+// GetDCPInfo returns the Disconnection Protect configuration of the account.
+func (c *Client) GetDCPInfo() (map[string]interface{}, error) {
+	return c.Request("GET", "/v5/account/query-dcp-info", nil)
+}
+
+// AllOrdersCanceller cancels all open orders of a category. Both *Client
+// and *DemoClient implement it.
+type AllOrdersCanceller interface {
+	CancelAllOrders(params map[string]interface{}) (map[string]interface{}, error)
+}
+
+// DCPWatchdogConfig configures a DCPWatchdog.
+type DCPWatchdogConfig struct {
+	// Categories whose orders are cancelled. Defaults to linear.
+	Categories []string
+	// SettleCoin is required to cancel all linear and inverse orders.
+	// Defaults to USDT.
+	SettleCoin string
+	// PingInterval is how often a ping is sent on the stream. Defaults to 5s.
+	PingInterval time.Duration
+	// Timeout is how long the stream may stay silent before orders are
+	// cancelled. Defaults to 15s.
+	Timeout time.Duration
+}
+
+// DCPWatchdog is a client-side complement to Bybit's Disconnection
+// Protect. It pings a private WebSocket and cancels all orders over REST
+// when the connection drops or the stream stops answering, covering
+// failures on our side that the exchange cannot see, such as a stalled
+// process holding an open socket.
+//
+// Feed every message received from WebSocket.OnMessage into Handle so the
+// watchdog sees the heartbeat.
+type DCPWatchdog struct {
+	client    AllOrdersCanceller
+	ws        *WebSocket
+	config    DCPWatchdogConfig
+	lastSeen  time.Time
+	triggered bool
+	onTrigger func(results map[string]interface{}, err error)
+	stop      chan struct{}
+	mu        sync.Mutex
+}
+
+// NewDCPWatchdog creates a watchdog for a private connection. Call Start to
+// begin monitoring.
+func NewDCPWatchdog(client AllOrdersCanceller, ws *WebSocket, config DCPWatchdogConfig) *DCPWatchdog {
+	if len(config.Categories) == 0 {
+		config.Categories = []string{CategoryLinear}
+	}
+	if config.SettleCoin == "" {
+		config.SettleCoin = "USDT"
+	}
+	if config.PingInterval == 0 {
+		config.PingInterval = 5 * time.Second
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 15 * time.Second
+	}
+
+	return &DCPWatchdog{
+		client: client,
+		ws:     ws,
+		config: config,
+	}
+}
+
+// OnTrigger registers a callback fired after the watchdog cancelled orders.
+// results holds the cancel-all response per category.
+func (w *DCPWatchdog) OnTrigger(callback func(results map[string]interface{}, err error)) {
+	w.mu.Lock()
+	w.onTrigger = callback
+	w.mu.Unlock()
+}
+
+// Handle records a heartbeat. Any message from the stream counts, including
+// pong replies; error messages reported by Listen do not.
+func (w *DCPWatchdog) Handle(message map[string]interface{}) {
+	if isErr, ok := message["error"].(bool); ok && isErr {
+		return
+	}
+
+	w.mu.Lock()
+	w.lastSeen = time.Now()
+	w.triggered = false
+	w.mu.Unlock()
+}
+
+// Start begins pinging and monitoring the stream.
+func (w *DCPWatchdog) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stop != nil {
+		return
+	}
+	w.lastSeen = time.Now()
+	w.triggered = false
+	w.stop = make(chan struct{})
+	go w.run(w.stop)
+}
+
+// Stop ends monitoring without cancelling orders.
+func (w *DCPWatchdog) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+}
+
+func (w *DCPWatchdog) run(stop chan struct{}) {
+	ticker := time.NewTicker(w.config.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		// Ping never reconnects, so a dropped connection fails here too;
+		// checking first avoids counting on that.
+		down := !w.ws.IsConnected()
+		if !down {
+			down = w.ws.Ping() != nil
+		}
+
+		w.mu.Lock()
+		silent := time.Since(w.lastSeen) > w.config.Timeout
+		fire := (down || silent) && !w.triggered
+		if fire {
+			w.triggered = true
+		}
+		w.mu.Unlock()
+
+		if fire {
+			w.CancelAll()
+		}
+	}
+}
+
+// CancelAll cancels the open orders of every configured category over REST
+// and fires the OnTrigger callback. The watchdog calls it when the
+// heartbeat is lost; it can also be called directly.
+func (w *DCPWatchdog) CancelAll() (map[string]interface{}, error) {
+	results := make(map[string]interface{})
+	var firstErr error
+
+	for _, category := range w.config.Categories {
+		params := map[string]interface{}{"category": category}
+		if category == CategoryLinear || category == CategoryInverse {
+			params["settleCoin"] = w.config.SettleCoin
+		}
+
+		res, err := w.client.CancelAllOrders(params)
+		if err == nil {
+			_, err = checkResponse(res)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("cancel all %s orders: %w", category, err)
+		}
+		results[category] = res
+	}
+
+	w.mu.Lock()
+	callback := w.onTrigger
+	w.mu.Unlock()
+
+	if callback != nil {
+		callback(results, firstErr)
+	}
+
+	return results, firstErr
+}
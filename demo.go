@@ -3,6 +3,7 @@ package bybit
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -17,7 +18,12 @@ type DemoClient struct {
 	*Client
 }
 
+// NewDemoClient returns a client for the demo trading host. Demo trading
+// has no testnet, so a config with Testnet set is rejected.
 func NewDemoClient(config ClientConfig) (*DemoClient, error) {
+	if config.Testnet {
+		return nil, fmt.Errorf("demo trading does not run on testnet")
+	}
 	client, err := NewClient(config)
 	if err != nil {
 		return nil, err
@@ -34,6 +40,24 @@ func (dc *DemoClient) WebSocketURL() string {
 	return DemoWebSocketURL
 }
 
+// NewPrivateWebSocket returns a private WebSocket authenticated with the
+// demo API key, delivering order, position, execution and wallet updates
+// of the demo account.
+func (dc *DemoClient) NewPrivateWebSocket() *WebSocket {
+	return NewWebSocket(WebSocketConfig{
+		APIKey:    dc.apiKey,
+		APISecret: dc.apiSecret,
+		IsPrivate: true,
+		Demo:      true,
+	})
+}
+
+// NewPublicWebSocket returns a public WebSocket for market data. Demo
+// trading has no public streams of its own, so it connects to mainnet.
+func (dc *DemoClient) NewPublicWebSocket() *WebSocket {
+	return NewWebSocket(WebSocketConfig{})
+}
+
 func (dc *DemoClient) Request(method, path string, params map[string]interface{}) (map[string]interface{}, error) {
 	method = strings.ToUpper(method)
 	fullURL := dc.BaseURI() + path
@@ -221,6 +245,41 @@ func (dc *DemoClient) GetSpotMarginStatus() (map[string]interface{}, error) {
 	return dc.Request("GET", "/v5/spot-margin-trade/state", nil)
 }
 
+// The typed account helpers of Client call its own raw methods and would
+// reach mainnet when promoted to DemoClient, so the ones demo trading
+// supports are wrapped here over the demo host.
+
+// AccountConfig returns the margin mode and account type of the demo account.
+func (dc *DemoClient) AccountConfig() (*AccountInfo, error) {
+	return accountInfoFromResponse(dc.GetAccountInfo())
+}
+
+// SwitchMarginMode sets the margin mode of the demo account. A rejected
+// switch returns a *MarginModeError listing the reasons.
+func (dc *DemoClient) SwitchMarginMode(mode MarginMode) error {
+	return marginModeFromResponse(dc.SetMarginMode(marginModeParams(mode)))
+}
+
+// SetSpotHedgingEnabled turns spot hedging on or off for the demo account.
+func (dc *DemoClient) SetSpotHedgingEnabled(on bool) error {
+	return errorFromResponse(dc.SetSpotHedging(spotHedgingParams(on)))
+}
+
+// Collateral returns the collateral settings of the demo account.
+func (dc *DemoClient) Collateral(currency string) ([]CollateralInfo, error) {
+	return collateralFromResponse(dc.GetCollateralInfo(collateralParams(currency)))
+}
+
+// SetCollateral turns a coin on or off as collateral of the demo account.
+func (dc *DemoClient) SetCollateral(coin string, on bool) error {
+	return errorFromResponse(dc.SetCollateralCoin(collateralSwitchParams(coin, on)))
+}
+
+// BorrowHistory returns a page of interest records of the demo account.
+func (dc *DemoClient) BorrowHistory(q BorrowHistoryQuery) ([]BorrowRecord, string, error) {
+	return borrowHistoryFromResponse(dc.GetBorrowHistory(q.params()))
+}
+
 type DemoFundRequest struct {
 	Coin      string `json:"coin"`
 	AmountStr string `json:"amountStr"`
@@ -249,11 +308,11 @@ func (dc *DemoClient) CreateDemoAPIKey(mainnetClient *Client, demoUID string, pa
 		params = map[string]interface{}{}
 	}
 	params["subuid"] = demoUID
-	return mainnetClient.Request("POST", "/v5/user/create-sub-api", params)
+	return mainnetClient.CreateSubAPIKey(params)
 }
 
 func (dc *DemoClient) UpdateDemoAPIKey(mainnetClient *Client, params map[string]interface{}) (map[string]interface{}, error) {
-	return mainnetClient.Request("POST", "/v5/user/update-sub-api", params)
+	return mainnetClient.UpdateSubAPIKey(params)
 }
 
 func (dc *DemoClient) GetAPIKeyInfo() (map[string]interface{}, error) {
@@ -261,5 +320,5 @@ func (dc *DemoClient) GetAPIKeyInfo() (map[string]interface{}, error) {
 }
 
 func (dc *DemoClient) DeleteDemoAPIKey(mainnetClient *Client, params map[string]interface{}) (map[string]interface{}, error) {
-	return mainnetClient.Request("POST", "/v5/user/delete-sub-api", params)
+	return mainnetClient.DeleteSubAPIKey(params)
 }
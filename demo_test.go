@@ -0,0 +1,49 @@
+package bybit
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDemoClientAccountHelpers(t *testing.T) {
+	var hosts []string
+	dc, err := NewDemoClient(ClientConfig{
+		APIKey:    "key",
+		APISecret: "secret",
+		HTTPClient: fakeHTTPClient(func(req *http.Request) string {
+			hosts = append(hosts, req.URL.Scheme+"://"+req.URL.Host)
+			return `{"retCode":0,"retMsg":"OK","result":{"marginMode":"REGULAR_MARGIN","list":[]}}`
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := dc.AccountConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dc.Collateral(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := dc.BorrowHistory(BorrowHistoryQuery{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dc.SetCollateral("BTC", true); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hosts) != 4 {
+		t.Fatalf("requests = %d, want 4", len(hosts))
+	}
+	for _, host := range hosts {
+		if host != DemoBaseURL {
+			t.Errorf("request sent to %s, want %s", host, DemoBaseURL)
+		}
+	}
+}
+
+func TestNewDemoClientRejectsTestnet(t *testing.T) {
+	if _, err := NewDemoClient(ClientConfig{APIKey: "key", APISecret: "secret", Testnet: true}); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,475 @@
+package bybit
+
+import (
+	"fmt"
+	"time"
+)
+
+// DepositStatus is the status of an on-chain deposit.
+type DepositStatus int
+
+// On-chain deposit statuses.
+const (
+	DepositStatusUnknown        DepositStatus = 0
+	DepositStatusToBeConfirmed  DepositStatus = 1
+	DepositStatusProcessing     DepositStatus = 2
+	DepositStatusSuccess        DepositStatus = 3
+	DepositStatusFailed         DepositStatus = 4
+	DepositStatusPendingCredit  DepositStatus = 10011
+	DepositStatusCreditedToFund DepositStatus = 10012
+)
+
+func (s DepositStatus) String() string {
+	switch s {
+	case DepositStatusUnknown:
+		return "unknown"
+	case DepositStatusToBeConfirmed:
+		return "toBeConfirmed"
+	case DepositStatusProcessing:
+		return "processing"
+	case DepositStatusSuccess:
+		return "success"
+	case DepositStatusFailed:
+		return "failed"
+	case DepositStatusPendingCredit:
+		return "pendingCredit"
+	case DepositStatusCreditedToFund:
+		return "creditedToFund"
+	}
+	return fmt.Sprintf("DepositStatus(%d)", int(s))
+}
+
+// InternalDepositStatus is the status of an off-chain deposit between
+// Bybit users.
+type InternalDepositStatus int
+
+// Internal deposit statuses.
+const (
+	InternalDepositStatusProcessing InternalDepositStatus = 1
+	InternalDepositStatusSuccess    InternalDepositStatus = 2
+	InternalDepositStatusFailed     InternalDepositStatus = 3
+)
+
+// WithdrawalStatus is the status of a withdrawal.
+type WithdrawalStatus string
+
+// Withdrawal statuses. Bybit spells success in lower case.
+const (
+	WithdrawalStatusSecurityCheck           WithdrawalStatus = "SecurityCheck"
+	WithdrawalStatusPending                 WithdrawalStatus = "Pending"
+	WithdrawalStatusSuccess                 WithdrawalStatus = "success"
+	WithdrawalStatusCancelByUser            WithdrawalStatus = "CancelByUser"
+	WithdrawalStatusReject                  WithdrawalStatus = "Reject"
+	WithdrawalStatusFail                    WithdrawalStatus = "Fail"
+	WithdrawalStatusBlockchainConfirmed     WithdrawalStatus = "BlockchainConfirmed"
+	WithdrawalStatusMoreInformationRequired WithdrawalStatus = "MoreInformationRequired"
+	WithdrawalStatusUnknown                 WithdrawalStatus = "Unknown"
+)
+
+// IsFinal reports whether the withdrawal can no longer change status.
+func (s WithdrawalStatus) IsFinal() bool {
+	switch s {
+	case WithdrawalStatusSuccess, WithdrawalStatusCancelByUser, WithdrawalStatusReject, WithdrawalStatusFail:
+		return true
+	}
+	return false
+}
+
+// Withdrawal types, as in WithdrawalQuery.WithdrawType.
+const (
+	WithdrawTypeOnChain  = 0
+	WithdrawTypeOffChain = 1
+	WithdrawTypeAll      = 2
+)
+
+// DepositChain is the deposit address of a coin on one chain.
+type DepositChain struct {
+	ChainType         string `json:"chainType"`
+	Chain             string `json:"chain"`
+	AddressDeposit    string `json:"addressDeposit"`
+	TagDeposit        string `json:"tagDeposit"`
+	BatchReleaseLimit string `json:"batchReleaseLimit"`
+	ContractAddress   string `json:"contractAddress"`
+}
+
+// DepositAddress is the result of the deposit address endpoints.
+type DepositAddress struct {
+	Coin   string
+	Chains []DepositChain
+}
+
+// DepositAddressOf returns the master account deposit addresses of a coin,
+// on every chain or on chain when set.
+func (c *Client) DepositAddressOf(coin, chain string) (*DepositAddress, error) {
+	params := map[string]interface{}{"coin": coin}
+	if chain != "" {
+		params["chainType"] = chain
+	}
+
+	res, err := c.GetDepositAddress(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Coin   string         `json:"coin"`
+		Chains []DepositChain `json:"chains"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return nil, err
+	}
+	return &DepositAddress{Coin: result.Coin, Chains: result.Chains}, nil
+}
+
+// SubDepositAddressOf returns the deposit address of a sub UID for a coin
+// on one chain.
+func (c *Client) SubDepositAddressOf(coin, chain, subMemberID string) (*DepositAddress, error) {
+	res, err := c.GetSubDepositAddress(map[string]interface{}{
+		"coin":        coin,
+		"chainType":   chain,
+		"subMemberId": subMemberID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Coin   string       `json:"coin"`
+		Chains DepositChain `json:"chains"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return nil, err
+	}
+	return &DepositAddress{Coin: result.Coin, Chains: []DepositChain{result.Chains}}, nil
+}
+
+// DepositRecord is an on-chain deposit.
+type DepositRecord struct {
+	ID                string        `json:"id"`
+	Coin              string        `json:"coin"`
+	Chain             string        `json:"chain"`
+	Amount            string        `json:"amount"`
+	TxID              string        `json:"txID"`
+	Status            DepositStatus `json:"status"`
+	ToAddress         string        `json:"toAddress"`
+	Tag               string        `json:"tag"`
+	DepositFee        string        `json:"depositFee"`
+	SuccessAt         string        `json:"successAt"`
+	Confirmations     string        `json:"confirmations"`
+	TxIndex           string        `json:"txIndex"`
+	BlockHash         string        `json:"blockHash"`
+	BatchReleaseLimit string        `json:"batchReleaseLimit"`
+	DepositType       string        `json:"depositType"`
+}
+
+// InternalDepositRecord is an off-chain deposit from another Bybit user.
+type InternalDepositRecord struct {
+	ID          string                `json:"id"`
+	Type        int                   `json:"type"`
+	Coin        string                `json:"coin"`
+	Amount      string                `json:"amount"`
+	Status      InternalDepositStatus `json:"status"`
+	Address     string                `json:"address"`
+	CreatedTime string                `json:"createdTime"`
+	TxID        string                `json:"txID"`
+}
+
+// DepositQuery filters deposit records. All fields are optional; without
+// Start and End Bybit returns the last 30 days.
+type DepositQuery struct {
+	Coin  string
+	TxID  string
+	Start time.Time
+	End   time.Time
+	// Limit is the page size, 1-50.
+	Limit  int
+	Cursor string
+}
+
+func (q DepositQuery) params() map[string]interface{} {
+	params := map[string]interface{}{}
+	if q.Coin != "" {
+		params["coin"] = q.Coin
+	}
+	if q.TxID != "" {
+		params["txID"] = q.TxID
+	}
+	if !q.Start.IsZero() {
+		params["startTime"] = q.Start.UnixMilli()
+	}
+	if !q.End.IsZero() {
+		params["endTime"] = q.End.UnixMilli()
+	}
+	if q.Limit > 0 {
+		params["limit"] = q.Limit
+	}
+	if q.Cursor != "" {
+		params["cursor"] = q.Cursor
+	}
+	return params
+}
+
+// Deposits returns a page of on-chain deposits of the account and the
+// cursor of the next page.
+func (c *Client) Deposits(q DepositQuery) ([]DepositRecord, string, error) {
+	res, err := c.GetDepositRecords(q.params())
+	if err != nil {
+		return nil, "", err
+	}
+
+	var rows []DepositRecord
+	cursor, err := decodeRows(res, &rows)
+	return rows, cursor, err
+}
+
+// SubDeposits returns a page of on-chain deposits of a sub UID, queried
+// from the master account, and the cursor of the next page.
+func (c *Client) SubDeposits(subMemberID string, q DepositQuery) ([]DepositRecord, string, error) {
+	params := q.params()
+	params["subMemberId"] = subMemberID
+
+	res, err := c.GetSubDepositRecords(params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var rows []DepositRecord
+	cursor, err := decodeRows(res, &rows)
+	return rows, cursor, err
+}
+
+// InternalDeposits returns a page of off-chain deposits and the cursor of
+// the next page.
+func (c *Client) InternalDeposits(q DepositQuery) ([]InternalDepositRecord, string, error) {
+	res, err := c.GetInternalDepositRecords(q.params())
+	if err != nil {
+		return nil, "", err
+	}
+
+	var rows []InternalDepositRecord
+	cursor, err := decodeRows(res, &rows)
+	return rows, cursor, err
+}
+
+// CoinChain describes deposit and withdrawal support of a coin on one
+// chain. ChainDeposit and ChainWithdraw are "1" when enabled.
+type CoinChain struct {
+	Chain                 string `json:"chain"`
+	ChainType             string `json:"chainType"`
+	Confirmation          string `json:"confirmation"`
+	SafeConfirmNumber     string `json:"safeConfirmNumber"`
+	WithdrawFee           string `json:"withdrawFee"`
+	WithdrawPercentageFee string `json:"withdrawPercentageFee"`
+	DepositMin            string `json:"depositMin"`
+	WithdrawMin           string `json:"withdrawMin"`
+	MinAccuracy           string `json:"minAccuracy"`
+	ChainDeposit          string `json:"chainDeposit"`
+	ChainWithdraw         string `json:"chainWithdraw"`
+	ContractAddress       string `json:"contractAddress"`
+}
+
+// CoinInfo is an item of coin/query-info.
+type CoinInfo struct {
+	Name         string      `json:"name"`
+	Coin         string      `json:"coin"`
+	RemainAmount string      `json:"remainAmount"`
+	Chains       []CoinChain `json:"chains"`
+}
+
+// Coins returns the chains and limits of every coin, or of one coin when
+// coin is set.
+func (c *Client) Coins(coin string) ([]CoinInfo, error) {
+	params := map[string]interface{}{}
+	if coin != "" {
+		params["coin"] = coin
+	}
+
+	res, err := c.GetCoinInfo(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []CoinInfo
+	_, err = decodeRows(res, &rows)
+	return rows, err
+}
+
+// WithdrawableBalance is the withdrawable amount of a coin in one wallet.
+type WithdrawableBalance struct {
+	Coin               string `json:"coin"`
+	WithdrawableAmount string `json:"withdrawableAmount"`
+	AvailableBalance   string `json:"availableBalance"`
+}
+
+// Withdrawable is the result of withdraw/withdrawable-amount.
+type Withdrawable struct {
+	// LimitAmountUsd is the remaining daily withdrawal limit in USD.
+	LimitAmountUsd string `json:"limitAmountUsd"`
+	// Wallets is keyed by wallet: SPOT, FUND or UTA.
+	Wallets map[string]WithdrawableBalance `json:"withdrawableAmount"`
+}
+
+// WithdrawableAmount returns how much of a coin can be withdrawn from each
+// wallet.
+func (c *Client) WithdrawableAmount(coin string) (*Withdrawable, error) {
+	res, err := c.GetWithdrawableAmount(map[string]interface{}{"coin": coin})
+	if err != nil {
+		return nil, err
+	}
+
+	var result Withdrawable
+	if err := decodeResult(res, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// WithdrawRequest describes an on-chain or internal withdrawal.
+type WithdrawRequest struct {
+	Coin    string
+	Chain   string
+	Address string
+	Tag     string
+	Amount  string
+	// ForceChain is 0 to settle internally when the address belongs to a
+	// Bybit user, 1 to force an on-chain withdrawal, 2 to withdraw to a
+	// Bybit UID.
+	ForceChain int
+	// AccountType is SPOT, FUND, UTA or FUND,UTA. Defaults to FUND on
+	// Bybit's side.
+	AccountType string
+	// FeeType 1 deducts the fee from Amount instead of adding it.
+	FeeType int
+	// RequestID deduplicates requests within 30 minutes.
+	RequestID string
+}
+
+// Withdraw submits a withdrawal and returns its id. The address must be on
+// the account's withdrawal whitelist.
+func (c *Client) Withdraw(req WithdrawRequest) (string, error) {
+	params := map[string]interface{}{
+		"coin":      req.Coin,
+		"address":   req.Address,
+		"amount":    req.Amount,
+		"timestamp": time.Now().UnixMilli(),
+	}
+	if req.Chain != "" {
+		params["chain"] = req.Chain
+	}
+	if req.Tag != "" {
+		params["tag"] = req.Tag
+	}
+	if req.ForceChain != 0 {
+		params["forceChain"] = req.ForceChain
+	}
+	if req.AccountType != "" {
+		params["accountType"] = req.AccountType
+	}
+	if req.FeeType != 0 {
+		params["feeType"] = req.FeeType
+	}
+	if req.RequestID != "" {
+		params["requestId"] = req.RequestID
+	}
+
+	res, err := c.CreateWithdrawal(params)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return "", err
+	}
+	return result.ID, nil
+}
+
+// CancelWithdraw cancels a withdrawal that has not been sent yet. It
+// reports whether the cancellation succeeded.
+func (c *Client) CancelWithdraw(id string) (bool, error) {
+	res, err := c.CancelWithdrawal(map[string]interface{}{"id": id})
+	if err != nil {
+		return false, err
+	}
+
+	var result struct {
+		Status int `json:"status"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return false, err
+	}
+	return result.Status == 1, nil
+}
+
+// WithdrawalRecord is an item of withdraw/query-record.
+type WithdrawalRecord struct {
+	WithdrawID   string           `json:"withdrawId"`
+	TxID         string           `json:"txID"`
+	WithdrawType int              `json:"withdrawType"`
+	Coin         string           `json:"coin"`
+	Chain        string           `json:"chain"`
+	Amount       string           `json:"amount"`
+	WithdrawFee  string           `json:"withdrawFee"`
+	Status       WithdrawalStatus `json:"status"`
+	ToAddress    string           `json:"toAddress"`
+	Tag          string           `json:"tag"`
+	CreateTime   string           `json:"createTime"`
+	UpdateTime   string           `json:"updateTime"`
+}
+
+// WithdrawalQuery filters withdrawal records. All fields are optional;
+// without Start and End Bybit returns the last 30 days.
+type WithdrawalQuery struct {
+	WithdrawID string
+	TxID       string
+	Coin       string
+	// WithdrawType is one of the WithdrawType constants. Defaults to
+	// on-chain only.
+	WithdrawType int
+	Start        time.Time
+	End          time.Time
+	// Limit is the page size, 1-50.
+	Limit  int
+	Cursor string
+}
+
+// Withdrawals returns a page of withdrawals and the cursor of the next
+// page.
+func (c *Client) Withdrawals(q WithdrawalQuery) ([]WithdrawalRecord, string, error) {
+	params := map[string]interface{}{}
+	if q.WithdrawID != "" {
+		params["withdrawID"] = q.WithdrawID
+	}
+	if q.TxID != "" {
+		params["txID"] = q.TxID
+	}
+	if q.Coin != "" {
+		params["coin"] = q.Coin
+	}
+	if q.WithdrawType != 0 {
+		params["withdrawType"] = q.WithdrawType
+	}
+	if !q.Start.IsZero() {
+		params["startTime"] = q.Start.UnixMilli()
+	}
+	if !q.End.IsZero() {
+		params["endTime"] = q.End.UnixMilli()
+	}
+	if q.Limit > 0 {
+		params["limit"] = q.Limit
+	}
+	if q.Cursor != "" {
+		params["cursor"] = q.Cursor
+	}
+
+	res, err := c.GetWithdrawalRecords(params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var rows []WithdrawalRecord
+	cursor, err := decodeRows(res, &rows)
+	return rows, cursor, err
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	bybit "github.com/tigusigalpa/bybit-go"
+)
+
+func main() {
+	apiKey := os.Getenv("BYBIT_DEMO_API_KEY")
+	apiSecret := os.Getenv("BYBIT_DEMO_API_SECRET")
+
+	if apiKey == "" || apiSecret == "" {
+		log.Fatal("Please set BYBIT_DEMO_API_KEY and BYBIT_DEMO_API_SECRET environment variables")
+	}
+
+	fmt.Println("=== Bybit Go SDK - Demo Trading WebSocket Example ===\n")
+
+	demoClient, err := bybit.NewDemoClient(bybit.ClientConfig{
+		APIKey:    apiKey,
+		APISecret: apiSecret,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Private streams come from the demo environment, public market data
+	// from mainnet.
+	ws := demoClient.NewPrivateWebSocket()
+
+	fmt.Printf("🔌 Connecting to %s...\n", demoClient.WebSocketURL())
+
+	if err := ws.Connect(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ws.Subscribe([]string{"order", "position", "execution", "wallet"}); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("✅ Subscribed to demo order, position, execution and wallet updates")
+	fmt.Println("\n📡 Listening for messages...\n")
+
+	ws.OnMessage(func(data map[string]interface{}) {
+		if errorMsg, ok := data["error"].(bool); ok && errorMsg {
+			fmt.Printf("❌ Error: %v\n", data["message"])
+			return
+		}
+		if topic, ok := data["topic"].(string); ok {
+			fmt.Printf("📨 %s: %v\n", topic, data["data"])
+		}
+	})
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigChan
+		fmt.Println("\n\n🛑 Shutting down...")
+		ws.Close()
+		os.Exit(0)
+	}()
+
+	if err := ws.Listen(); err != nil {
+		log.Fatal(err)
+	}
+}
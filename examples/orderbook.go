@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	bybit "github.com/tigusigalpa/bybit-go"
+)
+
+func main() {
+	fmt.Println("=== Bybit Go SDK - Local Order Book Example ===\n")
+
+	ws := bybit.NewWebSocket(bybit.WebSocketConfig{
+		Testnet:   false,
+		Region:    "global",
+		IsPrivate: false,
+	})
+
+	book := bybit.NewOrderBook(ws)
+	book.OnResync(func(symbol string, err error) {
+		if err != nil {
+			fmt.Printf("❌ Resync of %s failed: %v\n", symbol, err)
+			return
+		}
+		fmt.Printf("⚠️  Sequence gap on %s, waiting for a new snapshot\n", symbol)
+	})
+
+	ws.OnMessage(func(data map[string]interface{}) {
+		if errorMsg, ok := data["error"].(bool); ok && errorMsg {
+			fmt.Printf("❌ Error: %v\n", data["message"])
+			return
+		}
+		book.Handle(data)
+	})
+
+	if err := ws.Connect(); err != nil {
+		log.Fatal(err)
+	}
+
+	ws.SubscribeOrderbook("BTCUSDT", 50)
+
+	go func() {
+		for range time.Tick(time.Second) {
+			bid, okBid := book.BestBid("BTCUSDT")
+			ask, okAsk := book.BestAsk("BTCUSDT")
+			if !okBid || !okAsk {
+				continue
+			}
+			micro, _ := book.Microprice("BTCUSDT")
+			vwap, err := book.VWAP("BTCUSDT", "Buy", 1)
+			if err != nil {
+				fmt.Printf("📖 Bid %.2f x %.4f | Ask %.2f x %.4f | Micro %.2f\n",
+					bid.Price, bid.Size, ask.Price, ask.Size, micro)
+				continue
+			}
+			fmt.Printf("📖 Bid %.2f x %.4f | Ask %.2f x %.4f | Micro %.2f | VWAP(1 BTC) %.2f\n",
+				bid.Price, bid.Size, ask.Price, ask.Size, micro, vwap)
+		}
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigChan
+		fmt.Println("\n\n🛑 Shutting down...")
+		ws.Close()
+		os.Exit(0)
+	}()
+
+	if err := ws.Listen(); err != nil {
+		log.Fatal(err)
+	}
+}
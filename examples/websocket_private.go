@@ -30,6 +30,10 @@ func main() {
 
 	fmt.Println("🔌 Connecting to Bybit Private WebSocket...")
 
+	if err := ws.Connect(); err != nil {
+		log.Fatal(err)
+	}
+
 	ws.SubscribePosition()
 	ws.SubscribeOrder()
 	ws.SubscribeExecution()
@@ -21,6 +21,10 @@ func main() {
 
 	fmt.Println("🔌 Connecting to Bybit WebSocket...")
 
+	if err := ws.Connect(); err != nil {
+		log.Fatal(err)
+	}
+
 	ws.SubscribeOrderbook("BTCUSDT", 50)
 	ws.SubscribeTrade("BTCUSDT")
 	ws.SubscribeTicker("BTCUSDT")
@@ -0,0 +1,193 @@
+package bybit
+
+import (
+	"time"
+)
+
+// executionWindow is the widest startTime/endTime range execution/list
+// accepts.
+const executionWindow = 7 * 24 * time.Hour
+
+// Execution types, as in Execution.ExecType.
+const (
+	ExecTypeTrade        = "Trade"
+	ExecTypeAdlTrade     = "AdlTrade"
+	ExecTypeFunding      = "Funding"
+	ExecTypeBustTrade    = "BustTrade"
+	ExecTypeDelivery     = "Delivery"
+	ExecTypeSettle       = "Settle"
+	ExecTypeBlockTrade   = "BlockTrade"
+	ExecTypeMovePosition = "MovePosition"
+)
+
+// Execution is an item of execution/list.
+type Execution struct {
+	Symbol          string `json:"symbol"`
+	OrderID         string `json:"orderId"`
+	OrderLinkID     string `json:"orderLinkId"`
+	Side            string `json:"side"`
+	OrderPrice      string `json:"orderPrice"`
+	OrderQty        string `json:"orderQty"`
+	LeavesQty       string `json:"leavesQty"`
+	CreateType      string `json:"createType"`
+	OrderType       string `json:"orderType"`
+	StopOrderType   string `json:"stopOrderType"`
+	ExecID          string `json:"execId"`
+	ExecType        string `json:"execType"`
+	ExecPrice       string `json:"execPrice"`
+	ExecQty         string `json:"execQty"`
+	ExecValue       string `json:"execValue"`
+	ExecFee         string `json:"execFee"`
+	ExecTime        string `json:"execTime"`
+	FeeRate         string `json:"feeRate"`
+	FeeCurrency     string `json:"feeCurrency"`
+	IsMaker         bool   `json:"isMaker"`
+	ClosedSize      string `json:"closedSize"`
+	MarkPrice       string `json:"markPrice"`
+	IndexPrice      string `json:"indexPrice"`
+	UnderlyingPrice string `json:"underlyingPrice"`
+	TradeIv         string `json:"tradeIv"`
+	MarkIv          string `json:"markIv"`
+	BlockTradeID    string `json:"blockTradeId"`
+	Seq             int64  `json:"seq"`
+}
+
+// ExecutionQuery filters execution/list. Category is required; the other
+// fields are optional. Without Start and End Bybit returns the last 7 days.
+type ExecutionQuery struct {
+	Category    string
+	Symbol      string
+	BaseCoin    string
+	OrderID     string
+	OrderLinkID string
+	ExecType    string
+	Start       time.Time
+	End         time.Time
+	// Limit is the page size, 1-100. Defaults to 50 on Bybit's side.
+	Limit  int
+	Cursor string
+}
+
+func (q ExecutionQuery) params() map[string]interface{} {
+	params := map[string]interface{}{"category": q.Category}
+	for key, value := range map[string]string{
+		"symbol":      q.Symbol,
+		"baseCoin":    q.BaseCoin,
+		"orderId":     q.OrderID,
+		"orderLinkId": q.OrderLinkID,
+		"execType":    q.ExecType,
+		"cursor":      q.Cursor,
+	} {
+		if value != "" {
+			params[key] = value
+		}
+	}
+	if !q.Start.IsZero() {
+		params["startTime"] = q.Start.UnixMilli()
+	}
+	if !q.End.IsZero() {
+		params["endTime"] = q.End.UnixMilli()
+	}
+	if q.Limit > 0 {
+		params["limit"] = q.Limit
+	}
+	return params
+}
+
+// Executions returns a page of executions, newest first, and the cursor of
+// the next page.
+func (c *Client) Executions(q ExecutionQuery) ([]Execution, string, error) {
+	res, err := c.GetTradeHistory(q.params())
+	if err != nil {
+		return nil, "", err
+	}
+
+	var list []Execution
+	cursor, err := decodeList(res, &list)
+	return list, cursor, err
+}
+
+// AllExecutions returns every execution matching q, newest first. It
+// follows nextPageCursor and splits ranges wider than 7 days into the
+// windows Bybit accepts.
+func (c *Client) AllExecutions(q ExecutionQuery) ([]Execution, error) {
+	if q.Limit == 0 {
+		q.Limit = 100
+	}
+	if q.Start.IsZero() || q.End.IsZero() {
+		return c.executionPages(q)
+	}
+
+	var executions []Execution
+	for end := q.End; !end.Before(q.Start); {
+		window := q
+		window.End = end
+		window.Start = end.Add(-executionWindow + time.Millisecond)
+		if window.Start.Before(q.Start) {
+			window.Start = q.Start
+		}
+		end = window.Start.Add(-time.Millisecond)
+
+		page, err := c.executionPages(window)
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, page...)
+	}
+	return executions, nil
+}
+
+func (c *Client) executionPages(q ExecutionQuery) ([]Execution, error) {
+	var executions []Execution
+	for {
+		page, cursor, err := c.Executions(q)
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, page...)
+
+		if cursor == "" || len(page) == 0 {
+			return executions, nil
+		}
+		q.Cursor = cursor
+	}
+}
+
+// OrderPreCheck is the result of order/pre-check: the account's initial
+// and maintenance margin rates before and after the order, in units of
+// 1e-4, e.g. 30 is a rate of 0.30%.
+type OrderPreCheck struct {
+	OrderID     string `json:"orderId"`
+	OrderLinkID string `json:"orderLinkId"`
+	PreImrE4    int64  `json:"preImrE4"`
+	PreMmrE4    int64  `json:"preMmrE4"`
+	PostImrE4   int64  `json:"postImrE4"`
+	PostMmrE4   int64  `json:"postMmrE4"`
+}
+
+// PreIMR returns the initial margin rate before the order as a fraction.
+func (p *OrderPreCheck) PreIMR() float64 { return float64(p.PreImrE4) / 1e4 }
+
+// PreMMR returns the maintenance margin rate before the order as a fraction.
+func (p *OrderPreCheck) PreMMR() float64 { return float64(p.PreMmrE4) / 1e4 }
+
+// PostIMR returns the initial margin rate after the order as a fraction.
+func (p *OrderPreCheck) PostIMR() float64 { return float64(p.PostImrE4) / 1e4 }
+
+// PostMMR returns the maintenance margin rate after the order as a fraction.
+func (p *OrderPreCheck) PostMMR() float64 { return float64(p.PostMmrE4) / 1e4 }
+
+// PreCheck previews the margin impact of an order without placing it.
+// params are the same as for CreateOrder.
+func (c *Client) PreCheck(params map[string]interface{}) (*OrderPreCheck, error) {
+	res, err := c.PreCheckOrder(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var check OrderPreCheck
+	if err := decodeResult(res, &check); err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
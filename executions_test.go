@@ -0,0 +1,113 @@
+package bybit
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc fakes the REST API in tests through ClientConfig.HTTPClient.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func fakeHTTPClient(handler func(req *http.Request) string) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(handler(req))),
+			Request:    req,
+		}, nil
+	})}
+}
+
+func TestAllExecutionsWindows(t *testing.T) {
+	end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name    string
+		start   time.Time
+		end     time.Time
+		pages   int
+		windows int
+	}{
+		{name: "within one window", start: end.Add(-3 * day), end: end, pages: 1, windows: 1},
+		{name: "exactly seven days", start: end.Add(-executionWindow + time.Millisecond), end: end, pages: 1, windows: 1},
+		{name: "seven days and a millisecond", start: end.Add(-executionWindow), end: end, pages: 1, windows: 2},
+		{name: "fifteen days", start: end.Add(-15 * day), end: end, pages: 1, windows: 3},
+		{name: "pages within each window", start: end.Add(-10 * day), end: end, pages: 3, windows: 2},
+		{name: "open ended", end: end, pages: 2, windows: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			type window struct{ start, end int64 }
+			var windows []window
+
+			client, err := NewClient(ClientConfig{
+				APIKey:    "key",
+				APISecret: "secret",
+				HTTPClient: fakeHTTPClient(func(req *http.Request) string {
+					query := req.URL.Query()
+					start, _ := strconv.ParseInt(query.Get("startTime"), 10, 64)
+					end, _ := strconv.ParseInt(query.Get("endTime"), 10, 64)
+
+					page := 1
+					if cursor := query.Get("cursor"); cursor != "" {
+						page, _ = strconv.Atoi(cursor)
+					} else {
+						windows = append(windows, window{start, end})
+					}
+					next := ""
+					if page < tt.pages {
+						next = strconv.Itoa(page + 1)
+					}
+					return fmt.Sprintf(`{"retCode":0,"retMsg":"OK","result":{"list":[{"execId":"%d-%d"}],"nextPageCursor":"%s"}}`, end, page, next)
+				}),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			executions, err := client.AllExecutions(ExecutionQuery{Category: CategoryLinear, Start: tt.start, End: tt.end})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(windows) != tt.windows {
+				t.Fatalf("windows = %d, want %d: %v", len(windows), tt.windows, windows)
+			}
+			if len(executions) != tt.windows*tt.pages {
+				t.Errorf("executions = %d, want %d", len(executions), tt.windows*tt.pages)
+			}
+			if tt.start.IsZero() {
+				return
+			}
+
+			// Windows run newest first, each at most seven days, and
+			// cover the range without overlapping.
+			if windows[0].end != tt.end.UnixMilli() {
+				t.Errorf("first window ends at %d, want %d", windows[0].end, tt.end.UnixMilli())
+			}
+			for i, w := range windows {
+				if span := time.Duration(w.end-w.start) * time.Millisecond; span >= executionWindow || span < 0 {
+					t.Errorf("window %d spans %s", i, span)
+				}
+				if i > 0 && w.end != windows[i-1].start-1 {
+					t.Errorf("window %d ends at %d, want %d", i, w.end, windows[i-1].start-1)
+				}
+			}
+			if last := windows[len(windows)-1]; last.start != tt.start.UnixMilli() {
+				t.Errorf("last window starts at %d, want %d", last.start, tt.start.UnixMilli())
+			}
+		})
+	}
+}
@@ -0,0 +1,504 @@
+package bybit
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kline series served by the market kline endpoints.
+const (
+	KlineVariantTrade        = ""
+	KlineVariantMark         = "mark"
+	KlineVariantIndex        = "index"
+	KlineVariantPremiumIndex = "premium-index"
+)
+
+// Output formats of FetchKlinesWith.
+const (
+	KlineFormatCSV   = "csv"
+	KlineFormatJSONL = "jsonl"
+)
+
+// klinePageLimit is the maximum number of candles per kline request.
+const klinePageLimit = 1000
+
+var klineVariantPaths = map[string]string{
+	KlineVariantTrade:        "/v5/market/kline",
+	KlineVariantMark:         "/v5/market/mark-price-kline",
+	KlineVariantIndex:        "/v5/market/index-price-kline",
+	KlineVariantPremiumIndex: "/v5/market/premium-index-price-kline",
+}
+
+// Kline is a single candle. Mark, index and premium index klines carry no
+// volume, so Volume and Turnover are zero for those variants.
+type Kline struct {
+	Start    time.Time
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+	Volume   float64
+	Turnover float64
+}
+
+// KlineGap is a range of missing candles, From being the start of the
+// first missing candle and To the start of the next candle present.
+type KlineGap struct {
+	From    time.Time
+	To      time.Time
+	Missing int
+}
+
+// KlineSeries is the result of a bulk kline download, oldest first.
+type KlineSeries struct {
+	Klines []Kline
+	Gaps   []KlineGap
+}
+
+// KlineRequest describes a bulk kline download.
+type KlineRequest struct {
+	Symbol   string
+	Category string
+	// Interval is Bybit's kline interval: 1, 3, 5, 15, 30, 60, 120, 240,
+	// 360, 720, D, W or M.
+	Interval string
+	From     time.Time
+	To       time.Time
+	// Variant selects trade (default), mark, index or premium-index klines.
+	Variant string
+	// OutputPath, when set, receives every page as it is downloaded. Rows
+	// are appended in download order, newest first within a page. If the
+	// file exists only the candles it lacks are downloaded: those above its
+	// newest row, those below its oldest row and those in gaps between its
+	// rows.
+	OutputPath string
+	// OutputFormat is KlineFormatCSV or KlineFormatJSONL. Defaults to the
+	// OutputPath extension, then CSV.
+	OutputFormat string
+	// PageDelay is a pause between requests to stay under rate limits.
+	PageDelay time.Duration
+}
+
+// FetchKlines downloads every trade kline of a symbol between from and to,
+// walking backwards in pages of 1000, and reports missing bars.
+func (c *Client) FetchKlines(symbol, category, interval string, from, to time.Time) (*KlineSeries, error) {
+	return c.FetchKlinesWith(KlineRequest{
+		Symbol:   symbol,
+		Category: category,
+		Interval: interval,
+		From:     from,
+		To:       to,
+	})
+}
+
+// FetchKlinesWith downloads a kline range as described by req.
+func (c *Client) FetchKlinesWith(req KlineRequest) (*KlineSeries, error) {
+	path, ok := klineVariantPaths[req.Variant]
+	if !ok {
+		return nil, fmt.Errorf("unknown kline variant %q", req.Variant)
+	}
+	if !req.From.Before(req.To) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+	if _, err := nextKlineStart(req.From, req.Interval); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]Kline)
+	ranges := []klineRange{{from: req.From, to: req.To}}
+
+	var out *klineFile
+	if req.OutputPath != "" {
+		var err error
+		out, err = openKlineFile(req.OutputPath, req.OutputFormat)
+		if err != nil {
+			return nil, err
+		}
+		defer out.Close()
+
+		for _, k := range out.existing {
+			seen[k.Start.UnixMilli()] = k
+		}
+		if len(out.existing) > 0 {
+			ranges, err = missingKlineRanges(out.existing, req.From, req.To, req.Interval)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, r := range ranges {
+		if err := c.fetchKlineRange(path, req, r, seen, out); err != nil {
+			return nil, err
+		}
+	}
+
+	klines := make([]Kline, 0, len(seen))
+	for _, k := range seen {
+		if !k.Start.Before(req.From) && !k.Start.After(req.To) {
+			klines = append(klines, k)
+		}
+	}
+	sort.Slice(klines, func(i, j int) bool { return klines[i].Start.Before(klines[j].Start) })
+
+	gaps, err := FindKlineGaps(klines, req.Interval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KlineSeries{Klines: klines, Gaps: gaps}, nil
+}
+
+// klineRange is an inclusive range of candle start times.
+type klineRange struct {
+	from time.Time
+	to   time.Time
+}
+
+// fetchKlineRange downloads the candles of r, walking backwards from r.to
+// in pages, and adds the ones not yet seen to seen and out.
+func (c *Client) fetchKlineRange(path string, req KlineRequest, r klineRange, seen map[int64]Kline, out *klineFile) error {
+	end := r.to
+	for !end.Before(r.from) {
+		params := map[string]interface{}{
+			"category": req.Category,
+			"symbol":   req.Symbol,
+			"interval": req.Interval,
+			"start":    r.from.UnixMilli(),
+			"end":      end.UnixMilli(),
+			"limit":    klinePageLimit,
+		}
+
+		res, err := c.Request("GET", path, params)
+		if err != nil {
+			return err
+		}
+		list, _, err := resultList(res)
+		if err != nil {
+			return err
+		}
+
+		page := make([]Kline, 0, len(list))
+		var oldest time.Time
+		for _, item := range list {
+			row, ok := item.([]interface{})
+			if !ok {
+				continue
+			}
+			k, err := parseKlineRow(row)
+			if err != nil {
+				return err
+			}
+			if oldest.IsZero() || k.Start.Before(oldest) {
+				oldest = k.Start
+			}
+			if _, dup := seen[k.Start.UnixMilli()]; dup {
+				continue
+			}
+			seen[k.Start.UnixMilli()] = k
+			page = append(page, k)
+		}
+
+		if out != nil && len(page) > 0 {
+			if err := out.Write(page); err != nil {
+				return err
+			}
+		}
+
+		if len(list) < klinePageLimit || oldest.IsZero() {
+			return nil
+		}
+		end = oldest.Add(-time.Millisecond)
+
+		if req.PageDelay > 0 {
+			time.Sleep(req.PageDelay)
+		}
+	}
+	return nil
+}
+
+// missingKlineRanges returns the parts of [from, to] not covered by the
+// klines of an existing file: above its newest row, inside its gaps and
+// below its oldest row, newest first.
+func missingKlineRanges(existing []Kline, from, to time.Time, interval string) ([]klineRange, error) {
+	sorted := make([]Kline, len(existing))
+	copy(sorted, existing)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var ranges []klineRange
+	add := func(r klineRange) {
+		if r.from.Before(from) {
+			r.from = from
+		}
+		if r.to.After(to) {
+			r.to = to
+		}
+		if !r.to.Before(r.from) {
+			ranges = append(ranges, r)
+		}
+	}
+
+	next, err := nextKlineStart(sorted[len(sorted)-1].Start, interval)
+	if err != nil {
+		return nil, err
+	}
+	add(klineRange{from: next, to: to})
+
+	gaps, err := FindKlineGaps(sorted, interval)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(gaps) - 1; i >= 0; i-- {
+		add(klineRange{from: gaps[i].From, to: gaps[i].To.Add(-time.Millisecond)})
+	}
+
+	add(klineRange{from: from, to: sorted[0].Start.Add(-time.Millisecond)})
+	return ranges, nil
+}
+
+// FindKlineGaps returns the missing candles between consecutive klines,
+// which must be sorted oldest first.
+func FindKlineGaps(klines []Kline, interval string) ([]KlineGap, error) {
+	var gaps []KlineGap
+	for i := 1; i < len(klines); i++ {
+		expected, err := nextKlineStart(klines[i-1].Start, interval)
+		if err != nil {
+			return nil, err
+		}
+
+		missing := 0
+		for t := expected; t.Before(klines[i].Start); {
+			missing++
+			t, _ = nextKlineStart(t, interval)
+		}
+		if missing > 0 {
+			gaps = append(gaps, KlineGap{From: expected, To: klines[i].Start, Missing: missing})
+		}
+	}
+	return gaps, nil
+}
+
+// nextKlineStart returns the start of the candle following start.
+func nextKlineStart(start time.Time, interval string) (time.Time, error) {
+	if interval == "M" {
+		return start.UTC().AddDate(0, 1, 0), nil
+	}
+	d, err := klineIntervalDuration(interval)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return start.Add(d), nil
+}
+
+// klineIntervalDuration returns the length of a kline interval. Monthly
+// candles have no fixed length and are rejected.
+func klineIntervalDuration(interval string) (time.Duration, error) {
+	switch interval {
+	case "D":
+		return 24 * time.Hour, nil
+	case "W":
+		return 7 * 24 * time.Hour, nil
+	case "M":
+		return 0, fmt.Errorf("kline interval M has no fixed duration")
+	}
+
+	minutes, err := strconv.Atoi(interval)
+	if err != nil || minutes <= 0 {
+		return 0, fmt.Errorf("invalid kline interval %q", interval)
+	}
+	return time.Duration(minutes) * time.Minute, nil
+}
+
+// parseKlineRow parses [start, open, high, low, close, volume, turnover];
+// mark and index rows stop after close.
+func parseKlineRow(row []interface{}) (Kline, error) {
+	if len(row) < 5 {
+		return Kline{}, fmt.Errorf("invalid kline row: %v", row)
+	}
+
+	values := make([]float64, 6)
+	for i := 1; i < len(row) && i <= 6; i++ {
+		s, _ := row[i].(string)
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return Kline{}, fmt.Errorf("invalid kline value %q: %w", s, err)
+		}
+		values[i-1] = f
+	}
+
+	return Kline{
+		Start:    time.UnixMilli(toInt64(row[0])).UTC(),
+		Open:     values[0],
+		High:     values[1],
+		Low:      values[2],
+		Close:    values[3],
+		Volume:   values[4],
+		Turnover: values[5],
+	}, nil
+}
+
+// klineFile appends klines to a CSV or JSON Lines file.
+type klineFile struct {
+	file     *os.File
+	format   string
+	csv      *csv.Writer
+	existing []Kline
+}
+
+type klineJSON struct {
+	Start    int64   `json:"start"`
+	Open     float64 `json:"open"`
+	High     float64 `json:"high"`
+	Low      float64 `json:"low"`
+	Close    float64 `json:"close"`
+	Volume   float64 `json:"volume"`
+	Turnover float64 `json:"turnover"`
+}
+
+var klineCSVHeader = []string{"start", "open", "high", "low", "close", "volume", "turnover"}
+
+func openKlineFile(path, format string) (*klineFile, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".jsonl", ".ndjson":
+			format = KlineFormatJSONL
+		default:
+			format = KlineFormatCSV
+		}
+	}
+	if format != KlineFormatCSV && format != KlineFormatJSONL {
+		return nil, fmt.Errorf("unknown kline output format %q", format)
+	}
+
+	existing, err := readKlineFile(path, format)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	kf := &klineFile{file: file, format: format, existing: existing}
+	if format == KlineFormatCSV {
+		kf.csv = csv.NewWriter(file)
+		if info, err := file.Stat(); err == nil && info.Size() == 0 {
+			kf.csv.Write(klineCSVHeader)
+		}
+	}
+	return kf, nil
+}
+
+// readKlineFile loads the rows of an existing output file in file order.
+func readKlineFile(path, format string) ([]Kline, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var klines []Kline
+
+	if format == KlineFormatJSONL {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var row klineJSON
+			if err := json.Unmarshal([]byte(line), &row); err != nil {
+				return nil, fmt.Errorf("resume %s: %w", path, err)
+			}
+			klines = append(klines, Kline{
+				Start:    time.UnixMilli(row.Start).UTC(),
+				Open:     row.Open,
+				High:     row.High,
+				Low:      row.Low,
+				Close:    row.Close,
+				Volume:   row.Volume,
+				Turnover: row.Turnover,
+			})
+		}
+		return klines, scanner.Err()
+	}
+
+	reader := csv.NewReader(file)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return klines, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("resume %s: %w", path, err)
+		}
+		if record[0] == klineCSVHeader[0] {
+			continue
+		}
+		row := make([]interface{}, len(record))
+		for i, v := range record {
+			row[i] = v
+		}
+		k, err := parseKlineRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("resume %s: %w", path, err)
+		}
+		klines = append(klines, k)
+	}
+}
+
+func (kf *klineFile) Write(klines []Kline) error {
+	if kf.format == KlineFormatJSONL {
+		w := bufio.NewWriter(kf.file)
+		enc := json.NewEncoder(w)
+		for _, k := range klines {
+			if err := enc.Encode(klineJSON{
+				Start:    k.Start.UnixMilli(),
+				Open:     k.Open,
+				High:     k.High,
+				Low:      k.Low,
+				Close:    k.Close,
+				Volume:   k.Volume,
+				Turnover: k.Turnover,
+			}); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	}
+
+	for _, k := range klines {
+		kf.csv.Write([]string{
+			strconv.FormatInt(k.Start.UnixMilli(), 10),
+			formatFloat(k.Open),
+			formatFloat(k.High),
+			formatFloat(k.Low),
+			formatFloat(k.Close),
+			formatFloat(k.Volume),
+			formatFloat(k.Turnover),
+		})
+	}
+	kf.csv.Flush()
+	return kf.csv.Error()
+}
+
+func (kf *klineFile) Close() error {
+	return kf.file.Close()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,175 @@
+package bybit
+
+import (
+	"testing"
+	"time"
+)
+
+var klineBase = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// minuteKlines returns one-minute klines starting at the given minute
+// offsets from klineBase.
+func minuteKlines(offsets ...int) []Kline {
+	klines := make([]Kline, len(offsets))
+	for i, offset := range offsets {
+		klines[i] = Kline{Start: klineBase.Add(time.Duration(offset) * time.Minute)}
+	}
+	return klines
+}
+
+func minute(offset int) time.Time {
+	return klineBase.Add(time.Duration(offset) * time.Minute)
+}
+
+func TestFindKlineGaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		klines   []Kline
+		interval string
+		want     []KlineGap
+	}{
+		{
+			name:     "contiguous",
+			klines:   minuteKlines(0, 1, 2, 3),
+			interval: "1",
+		},
+		{
+			name:     "single gap",
+			klines:   minuteKlines(0, 1, 4, 5),
+			interval: "1",
+			want:     []KlineGap{{From: minute(2), To: minute(4), Missing: 2}},
+		},
+		{
+			name:     "several gaps",
+			klines:   minuteKlines(0, 2, 3, 7),
+			interval: "1",
+			want: []KlineGap{
+				{From: minute(1), To: minute(2), Missing: 1},
+				{From: minute(4), To: minute(7), Missing: 3},
+			},
+		},
+		{
+			name:     "five minute interval",
+			klines:   minuteKlines(0, 5, 20),
+			interval: "5",
+			want:     []KlineGap{{From: minute(10), To: minute(20), Missing: 2}},
+		},
+		{
+			name: "monthly interval",
+			klines: []Kline{
+				{Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+				{Start: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+			},
+			interval: "M",
+			want: []KlineGap{{
+				From:    time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+				To:      time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+				Missing: 2,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindKlineGaps(tt.klines, tt.interval)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("gaps = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].From.Equal(tt.want[i].From) || !got[i].To.Equal(tt.want[i].To) || got[i].Missing != tt.want[i].Missing {
+					t.Errorf("gap %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, err := FindKlineGaps(minuteKlines(0, 1), "2h"); err == nil {
+		t.Error("expected an error for an invalid interval")
+	}
+}
+
+func TestMissingKlineRanges(t *testing.T) {
+	const ms = time.Millisecond
+
+	tests := []struct {
+		name     string
+		existing []Kline
+		from, to time.Time
+		want     []klineRange
+	}{
+		{
+			name:     "complete",
+			existing: minuteKlines(0, 1, 2, 3),
+			from:     minute(0),
+			to:       minute(3),
+		},
+		{
+			name:     "resume above the newest kline",
+			existing: minuteKlines(0, 1, 2),
+			from:     minute(0),
+			to:       minute(10),
+			want:     []klineRange{{from: minute(3), to: minute(10)}},
+		},
+		{
+			name:     "extend below the oldest kline",
+			existing: minuteKlines(5, 6, 7),
+			from:     minute(0),
+			to:       minute(7),
+			want:     []klineRange{{from: minute(0), to: minute(5).Add(-ms)}},
+		},
+		{
+			name:     "fill holes inside the saved range",
+			existing: minuteKlines(0, 1, 4, 5, 9),
+			from:     minute(0),
+			to:       minute(9),
+			want: []klineRange{
+				{from: minute(6), to: minute(9).Add(-ms)},
+				{from: minute(2), to: minute(4).Add(-ms)},
+			},
+		},
+		{
+			name:     "above, below and inside, newest first",
+			existing: minuteKlines(3, 5),
+			from:     minute(0),
+			to:       minute(8),
+			want: []klineRange{
+				{from: minute(6), to: minute(8)},
+				{from: minute(4), to: minute(5).Add(-ms)},
+				{from: minute(0), to: minute(3).Add(-ms)},
+			},
+		},
+		{
+			name:     "saved klines outside the requested window",
+			existing: minuteKlines(0, 1, 2, 20, 21),
+			from:     minute(5),
+			to:       minute(10),
+			want:     []klineRange{{from: minute(5), to: minute(10)}},
+		},
+		{
+			name:     "unsorted input",
+			existing: minuteKlines(2, 0, 1),
+			from:     minute(0),
+			to:       minute(2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := missingKlineRanges(tt.existing, tt.from, tt.to, "1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ranges = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].from.Equal(tt.want[i].from) || !got[i].to.Equal(tt.want[i].to) {
+					t.Errorf("range %d = %v..%v, want %v..%v", i, got[i].from, got[i].to, tt.want[i].from, tt.want[i].to)
+				}
+			}
+		})
+	}
+}
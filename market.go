@@ -0,0 +1,222 @@
+package bybit
+
+import (
+	"sort"
+	"time"
+)
+
+// Instrument statuses returned by instruments-info.
+const (
+	InstrumentStatusPreLaunch  = "PreLaunch"
+	InstrumentStatusTrading    = "Trading"
+	InstrumentStatusDelivering = "Delivering"
+	InstrumentStatusClosed     = "Closed"
+)
+
+// KlineQuery selects a page of candles. Start and End are optional; Limit
+// defaults to 200 on Bybit's side and is capped at 1000.
+type KlineQuery struct {
+	Category string
+	Symbol   string
+	Interval string
+	Start    time.Time
+	End      time.Time
+	Limit    int
+}
+
+func (q KlineQuery) params() map[string]interface{} {
+	params := map[string]interface{}{
+		"category": q.Category,
+		"symbol":   q.Symbol,
+		"interval": q.Interval,
+	}
+	if !q.Start.IsZero() {
+		params["start"] = q.Start.UnixMilli()
+	}
+	if !q.End.IsZero() {
+		params["end"] = q.End.UnixMilli()
+	}
+	if q.Limit > 0 {
+		params["limit"] = q.Limit
+	}
+	return params
+}
+
+// MarkPriceKlines returns mark price candles of a linear or inverse
+// contract, oldest first.
+func (c *Client) MarkPriceKlines(q KlineQuery) ([]Kline, error) {
+	return klinesFromResponse(c.GetMarkPriceKline(q.params()))
+}
+
+// IndexPriceKlines returns index price candles of a linear or inverse
+// contract, oldest first.
+func (c *Client) IndexPriceKlines(q KlineQuery) ([]Kline, error) {
+	return klinesFromResponse(c.GetIndexPriceKline(q.params()))
+}
+
+// PremiumIndexPriceKlines returns premium index candles of a linear
+// contract, oldest first. The premium index is the basis that drives the
+// funding rate.
+func (c *Client) PremiumIndexPriceKlines(q KlineQuery) ([]Kline, error) {
+	return klinesFromResponse(c.GetPremiumIndexPriceKline(q.params()))
+}
+
+func klinesFromResponse(res map[string]interface{}, err error) ([]Kline, error) {
+	if err != nil {
+		return nil, err
+	}
+	list, _, err := resultList(res)
+	if err != nil {
+		return nil, err
+	}
+
+	klines := make([]Kline, 0, len(list))
+	for _, item := range list {
+		row, ok := item.([]interface{})
+		if !ok {
+			continue
+		}
+		k, err := parseKlineRow(row)
+		if err != nil {
+			return nil, err
+		}
+		klines = append(klines, k)
+	}
+	sort.Slice(klines, func(i, j int) bool { return klines[i].Start.Before(klines[j].Start) })
+	return klines, nil
+}
+
+// InstrumentsQuery filters instruments-info. Category is required; the
+// other fields are optional.
+type InstrumentsQuery struct {
+	Category string
+	Symbol   string
+	Status   string
+	BaseCoin string
+}
+
+// LeverageFilter holds the leverage limits of a derivatives instrument.
+type LeverageFilter struct {
+	MinLeverage  string `json:"minLeverage"`
+	MaxLeverage  string `json:"maxLeverage"`
+	LeverageStep string `json:"leverageStep"`
+}
+
+// PriceFilter holds the price limits of an instrument. Spot instruments
+// only fill TickSize.
+type PriceFilter struct {
+	MinPrice string `json:"minPrice"`
+	MaxPrice string `json:"maxPrice"`
+	TickSize string `json:"tickSize"`
+}
+
+// LotSizeFilter holds the order size limits of an instrument. Spot
+// instruments use the precision and amount fields, derivatives the
+// quantity ones.
+type LotSizeFilter struct {
+	MinOrderQty         string `json:"minOrderQty"`
+	MaxOrderQty         string `json:"maxOrderQty"`
+	QtyStep             string `json:"qtyStep"`
+	PostOnlyMaxOrderQty string `json:"postOnlyMaxOrderQty"`
+	MaxMktOrderQty      string `json:"maxMktOrderQty"`
+	MinNotionalValue    string `json:"minNotionalValue"`
+	BasePrecision       string `json:"basePrecision"`
+	QuotePrecision      string `json:"quotePrecision"`
+	MinOrderAmt         string `json:"minOrderAmt"`
+	MaxOrderAmt         string `json:"maxOrderAmt"`
+}
+
+// RiskParameters holds the price limit ratios of an instrument.
+type RiskParameters struct {
+	PriceLimitRatioX string `json:"priceLimitRatioX"`
+	PriceLimitRatioY string `json:"priceLimitRatioY"`
+}
+
+// Instrument is an item of instruments-info for any category. Fields that
+// do not apply to the category are left empty.
+type Instrument struct {
+	Symbol      string `json:"symbol"`
+	DisplayName string `json:"displayName"`
+	Status      string `json:"status"`
+	BaseCoin    string `json:"baseCoin"`
+	QuoteCoin   string `json:"quoteCoin"`
+	SettleCoin  string `json:"settleCoin"`
+	SymbolType  string `json:"symbolType"`
+
+	// ContractType is LinearPerpetual, LinearFutures, InversePerpetual or
+	// InverseFutures for derivatives.
+	ContractType string `json:"contractType"`
+	// OptionsType is Call or Put for options.
+	OptionsType string `json:"optionsType"`
+
+	LaunchTime      string `json:"launchTime"`
+	DeliveryTime    string `json:"deliveryTime"`
+	DeliveryFeeRate string `json:"deliveryFeeRate"`
+	PriceScale      string `json:"priceScale"`
+
+	// FundingInterval is in minutes.
+	FundingInterval    int    `json:"fundingInterval"`
+	UpperFundingRate   string `json:"upperFundingRate"`
+	LowerFundingRate   string `json:"lowerFundingRate"`
+	UnifiedMarginTrade bool   `json:"unifiedMarginTrade"`
+	CopyTrading        string `json:"copyTrading"`
+	IsPreListing       bool   `json:"isPreListing"`
+
+	// Spot only.
+	Innovation    string `json:"innovation"`
+	MarginTrading string `json:"marginTrading"`
+	STTag         string `json:"stTag"`
+
+	LeverageFilter LeverageFilter `json:"leverageFilter"`
+	PriceFilter    PriceFilter    `json:"priceFilter"`
+	LotSizeFilter  LotSizeFilter  `json:"lotSizeFilter"`
+	RiskParameters RiskParameters `json:"riskParameters"`
+}
+
+// params returns the request parameters of q. limit is the page size,
+// which spot does not accept.
+func (q InstrumentsQuery) params(limit int) map[string]interface{} {
+	params := map[string]interface{}{"category": q.Category}
+	if q.Symbol != "" {
+		params["symbol"] = q.Symbol
+	}
+	if q.Status != "" {
+		params["status"] = q.Status
+	}
+	if q.BaseCoin != "" {
+		params["baseCoin"] = q.BaseCoin
+	}
+	if q.Category != CategorySpot {
+		params["limit"] = limit
+	}
+	return params
+}
+
+// Instruments returns every instrument matching q, following
+// nextPageCursor until the list is exhausted.
+func (c *Client) Instruments(q InstrumentsQuery) ([]Instrument, error) {
+	return instrumentPages(c.GetInstrumentsInfo, q.params(1000))
+}
+
+// instrumentPages collects the instruments of every page returned by fetch.
+func instrumentPages(fetch func(map[string]interface{}) (map[string]interface{}, error), params map[string]interface{}) ([]Instrument, error) {
+	var instruments []Instrument
+	for {
+		res, err := fetch(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Instrument
+		cursor, err := decodeList(res, &page)
+		if err != nil {
+			return nil, err
+		}
+		instruments = append(instruments, page...)
+
+		if cursor == "" || len(page) == 0 {
+			return instruments, nil
+		}
+		params["cursor"] = cursor
+	}
+}
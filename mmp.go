@@ -0,0 +1,344 @@
+package bybit
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CancelTypeMMP is the cancelType of option orders cancelled because
+// Market Maker Protection was triggered.
+const CancelTypeMMP = "CancelByMmpTriggered"
+
+// MMPConfig configures Market Maker Protection for the options of a base
+// coin. MMP freezes quoting for FrozenPeriod once the traded quantity or
+// delta within Window exceeds QtyLimit or DeltaLimit. A FrozenPeriod of 0
+// keeps trading frozen until ResetMMP is called.
+type MMPConfig struct {
+	BaseCoin     string
+	Window       time.Duration
+	FrozenPeriod time.Duration
+	QtyLimit     string
+	DeltaLimit   string
+}
+
+// MMPState is an item of account/mmp-state.
+type MMPState struct {
+	BaseCoin       string `json:"baseCoin"`
+	MMPEnabled     bool   `json:"mmpEnabled"`
+	Window         string `json:"window"`
+	FrozenPeriod   string `json:"frozenPeriod"`
+	QtyLimit       string `json:"qtyLimit"`
+	DeltaLimit     string `json:"deltaLimit"`
+	MMPFrozenUntil string `json:"mmpFrozenUntil"`
+	MMPFrozen      bool   `json:"mmpFrozen"`
+}
+
+// FrozenUntil returns the time the freeze ends, zero when not frozen or
+// frozen until reset.
+func (s *MMPState) FrozenUntil() time.Time {
+	if ms := toInt64(s.MMPFrozenUntil); ms > 0 {
+		return time.UnixMilli(ms)
+	}
+	return time.Time{}
+}
+
+// ConfigureMMP enables Market Maker Protection with the given limits.
+func (c *Client) ConfigureMMP(config MMPConfig) error {
+	res, err := c.SetMMP(map[string]interface{}{
+		"baseCoin":     config.BaseCoin,
+		"window":       strconv.FormatInt(config.Window.Milliseconds(), 10),
+		"frozenPeriod": strconv.FormatInt(config.FrozenPeriod.Milliseconds(), 10),
+		"qtyLimit":     config.QtyLimit,
+		"deltaLimit":   config.DeltaLimit,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = checkResponse(res)
+	return err
+}
+
+// UnfreezeMMP resets Market Maker Protection of a base coin, lifting a
+// freeze immediately.
+func (c *Client) UnfreezeMMP(baseCoin string) error {
+	res, err := c.ResetMMP(map[string]interface{}{"baseCoin": baseCoin})
+	if err != nil {
+		return err
+	}
+	_, err = checkResponse(res)
+	return err
+}
+
+// MMPStatus returns the Market Maker Protection state of a base coin, or
+// nil when MMP has never been configured for it.
+func (c *Client) MMPStatus(baseCoin string) (*MMPState, error) {
+	res, err := c.GetMMPState(map[string]interface{}{"baseCoin": baseCoin})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Result []MMPState `json:"result"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return nil, err
+	}
+	for i := range result.Result {
+		if result.Result[i].BaseCoin == baseCoin {
+			return &result.Result[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// MMP event sources.
+const (
+	MMPSourceOrder = "order"
+	MMPSourceState = "state"
+)
+
+// MMPFrozenEvent reports that Market Maker Protection froze quoting for a
+// base coin.
+type MMPFrozenEvent struct {
+	BaseCoin string
+	// Source is MMPSourceOrder when detected from orders cancelled with
+	// CancelTypeMMP on the order stream, MMPSourceState when detected by
+	// polling mmp-state.
+	Source string
+	// Orders lists the orders cancelled by MMP, for MMPSourceOrder.
+	Orders []MMPCancelledOrder
+	// FrozenUntil is set for MMPSourceState; zero means until reset.
+	FrozenUntil time.Time
+	Time        time.Time
+}
+
+// MMPCancelledOrder is an option order cancelled by Market Maker
+// Protection, as received on the order topic.
+type MMPCancelledOrder struct {
+	Category    string `json:"category"`
+	Symbol      string `json:"symbol"`
+	OrderID     string `json:"orderId"`
+	OrderLinkID string `json:"orderLinkId"`
+	OrderStatus string `json:"orderStatus"`
+	CancelType  string `json:"cancelType"`
+	UpdatedTime string `json:"updatedTime"`
+}
+
+// MMPStateSource queries MMP state. *Client implements it.
+type MMPStateSource interface {
+	MMPStatus(baseCoin string) (*MMPState, error)
+}
+
+// MMPMonitorConfig configures an MMPMonitor.
+type MMPMonitorConfig struct {
+	// BaseCoins are polled for their MMP state.
+	BaseCoins []string
+	// PollInterval is how often mmp-state is polled. Defaults to 5s.
+	PollInterval time.Duration
+}
+
+// MMPMonitor fires a callback when Market Maker Protection freezes
+// quoting, so a quoter can pause instead of sending orders that will be
+// rejected. Freezes are detected from the order stream as soon as MMP
+// cancels orders, with mmp-state polling as a fallback and to detect the
+// end of a freeze.
+//
+// mmp-state may lag the order stream, so a freeze detected from orders is
+// only lifted by polling once mmp-state confirmed it, or once the
+// configured frozen period has passed since the cancellations. With a
+// frozen period of 0 such a freeze lasts until Unfreeze is called.
+//
+// Feed every message of a private WebSocket subscribed to the order topic
+// into Handle.
+type MMPMonitor struct {
+	source     MMPStateSource
+	config     MMPMonitorConfig
+	frozen     map[string]mmpFreeze
+	onFreeze   func(MMPFrozenEvent)
+	onUnfreeze func(baseCoin string)
+	stop       chan struct{}
+	mu         sync.Mutex
+}
+
+// mmpFreeze records how and when a freeze was detected.
+type mmpFreeze struct {
+	source string
+	at     time.Time
+}
+
+// NewMMPMonitor creates a monitor. source may be nil to rely on the order
+// stream only; a freeze then lasts until Unfreeze is called.
+func NewMMPMonitor(source MMPStateSource, config MMPMonitorConfig) *MMPMonitor {
+	if config.PollInterval == 0 {
+		config.PollInterval = 5 * time.Second
+	}
+
+	return &MMPMonitor{
+		source: source,
+		config: config,
+		frozen: make(map[string]mmpFreeze),
+	}
+}
+
+// OnFreeze registers the callback fired when a base coin becomes frozen.
+func (m *MMPMonitor) OnFreeze(callback func(MMPFrozenEvent)) {
+	m.mu.Lock()
+	m.onFreeze = callback
+	m.mu.Unlock()
+}
+
+// OnUnfreeze registers the callback fired when a freeze ends.
+func (m *MMPMonitor) OnUnfreeze(callback func(baseCoin string)) {
+	m.mu.Lock()
+	m.onUnfreeze = callback
+	m.mu.Unlock()
+}
+
+// Frozen reports whether quoting of a base coin is currently frozen.
+func (m *MMPMonitor) Frozen(baseCoin string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.frozen[baseCoin]
+	return ok
+}
+
+// Handle inspects an order stream message for orders cancelled by MMP.
+func (m *MMPMonitor) Handle(message map[string]interface{}) {
+	topic, _ := message["topic"].(string)
+	if topic != "order" && topic != "order.option" {
+		return
+	}
+
+	var orders []MMPCancelledOrder
+	if err := DecodeStreamData(message, &orders); err != nil {
+		return
+	}
+
+	byCoin := make(map[string][]MMPCancelledOrder)
+	for _, order := range orders {
+		if order.CancelType != CancelTypeMMP {
+			continue
+		}
+		baseCoin := strings.SplitN(order.Symbol, "-", 2)[0]
+		byCoin[baseCoin] = append(byCoin[baseCoin], order)
+	}
+
+	for baseCoin, cancelled := range byCoin {
+		m.freeze(MMPFrozenEvent{
+			BaseCoin: baseCoin,
+			Source:   MMPSourceOrder,
+			Orders:   cancelled,
+			Time:     time.Now(),
+		})
+	}
+}
+
+// Unfreeze marks a base coin as no longer frozen, e.g. after UnfreezeMMP.
+func (m *MMPMonitor) Unfreeze(baseCoin string) {
+	m.mu.Lock()
+	_, wasFrozen := m.frozen[baseCoin]
+	delete(m.frozen, baseCoin)
+	callback := m.onUnfreeze
+	m.mu.Unlock()
+
+	if wasFrozen && callback != nil {
+		callback(baseCoin)
+	}
+}
+
+// Start begins polling mmp-state. It does nothing without a source.
+func (m *MMPMonitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stop != nil || m.source == nil {
+		return
+	}
+	m.stop = make(chan struct{})
+	go m.poll(m.stop)
+}
+
+// Stop ends polling.
+func (m *MMPMonitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+func (m *MMPMonitor) poll(stop chan struct{}) {
+	ticker := time.NewTicker(m.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		m.Check()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check polls the MMP state of every configured base coin once and fires
+// the callbacks for any change.
+func (m *MMPMonitor) Check() {
+	for _, baseCoin := range m.config.BaseCoins {
+		state, err := m.source.MMPStatus(baseCoin)
+		if err != nil || state == nil {
+			continue
+		}
+
+		if state.MMPFrozen {
+			m.freeze(MMPFrozenEvent{
+				BaseCoin:    baseCoin,
+				Source:      MMPSourceState,
+				FrozenUntil: state.FrozenUntil(),
+				Time:        time.Now(),
+			})
+		} else if m.thawed(baseCoin, state, time.Now()) {
+			m.Unfreeze(baseCoin)
+		}
+	}
+}
+
+// thawed reports whether a base coin that mmp-state reports as not frozen
+// can be unfrozen at now. Freezes seen only on the order stream may not
+// have reached mmp-state yet, so they are kept for the frozen period.
+func (m *MMPMonitor) thawed(baseCoin string, state *MMPState, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.frozen[baseCoin]
+	if !ok || f.source == MMPSourceState {
+		return true
+	}
+	period := time.Duration(toInt64(state.FrozenPeriod)) * time.Millisecond
+	return period > 0 && now.After(f.at.Add(period))
+}
+
+// freeze records a freeze and fires OnFreeze if the base coin was not
+// frozen yet. A freeze confirmed by mmp-state counts as detected there.
+func (m *MMPMonitor) freeze(event MMPFrozenEvent) {
+	m.mu.Lock()
+	f, wasFrozen := m.frozen[event.BaseCoin]
+	if !wasFrozen {
+		f = mmpFreeze{source: event.Source, at: event.Time}
+	}
+	if event.Source == MMPSourceState {
+		f.source = MMPSourceState
+	}
+	m.frozen[event.BaseCoin] = f
+	callback := m.onFreeze
+	m.mu.Unlock()
+
+	if !wasFrozen && callback != nil {
+		callback(event)
+	}
+}
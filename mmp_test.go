@@ -0,0 +1,138 @@
+package bybit
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeMMPSource map[string]*MMPState
+
+func (s fakeMMPSource) MMPStatus(baseCoin string) (*MMPState, error) {
+	return s[baseCoin], nil
+}
+
+func mmpOrderMessage(cancelTypes map[string]string) map[string]interface{} {
+	var data []interface{}
+	for symbol, cancelType := range cancelTypes {
+		data = append(data, map[string]interface{}{
+			"category":    CategoryOption,
+			"symbol":      symbol,
+			"orderStatus": "Cancelled",
+			"cancelType":  cancelType,
+		})
+	}
+	return map[string]interface{}{"topic": "order", "data": data}
+}
+
+func TestMMPMonitorHandle(t *testing.T) {
+	m := NewMMPMonitor(nil, MMPMonitorConfig{})
+
+	var events []MMPFrozenEvent
+	m.OnFreeze(func(e MMPFrozenEvent) { events = append(events, e) })
+
+	m.Handle(mmpOrderMessage(map[string]string{
+		"BTC-27DEC24-60000-C": CancelTypeMMP,
+		"BTC-27DEC24-70000-C": CancelTypeMMP,
+		"ETH-27DEC24-3000-C":  "CancelByUser",
+	}))
+	m.Handle(mmpOrderMessage(map[string]string{"BTC-27DEC24-80000-P": CancelTypeMMP}))
+
+	if len(events) != 1 {
+		t.Fatalf("freeze events = %d, want 1", len(events))
+	}
+	if e := events[0]; e.BaseCoin != "BTC" || e.Source != MMPSourceOrder || len(e.Orders) != 2 {
+		t.Errorf("event = %+v", e)
+	}
+	if !m.Frozen("BTC") || m.Frozen("ETH") {
+		t.Errorf("frozen BTC %v, ETH %v, want true and false", m.Frozen("BTC"), m.Frozen("ETH"))
+	}
+}
+
+func TestMMPMonitorCheck(t *testing.T) {
+	frozenBTC := fakeMMPSource{"BTC": {BaseCoin: "BTC", MMPFrozen: true, FrozenPeriod: "60000"}}
+	thawedBTC := fakeMMPSource{"BTC": {BaseCoin: "BTC", FrozenPeriod: "60000"}}
+	untilReset := fakeMMPSource{"BTC": {BaseCoin: "BTC", FrozenPeriod: "0"}}
+
+	tests := []struct {
+		name       string
+		fromOrders bool
+		polls      []fakeMMPSource
+		frozen     bool
+		unfreezes  int
+	}{
+		{
+			name:   "state freeze",
+			polls:  []fakeMMPSource{frozenBTC},
+			frozen: true,
+		},
+		{
+			name:      "state freeze ends",
+			polls:     []fakeMMPSource{frozenBTC, thawedBTC},
+			unfreezes: 1,
+		},
+		{
+			name:       "order freeze not yet in state",
+			fromOrders: true,
+			polls:      []fakeMMPSource{thawedBTC},
+			frozen:     true,
+		},
+		{
+			name:       "order freeze confirmed then ended",
+			fromOrders: true,
+			polls:      []fakeMMPSource{thawedBTC, frozenBTC, thawedBTC},
+			unfreezes:  1,
+		},
+		{
+			name:       "order freeze until reset",
+			fromOrders: true,
+			polls:      []fakeMMPSource{untilReset},
+			frozen:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := fakeMMPSource{}
+			m := NewMMPMonitor(source, MMPMonitorConfig{BaseCoins: []string{"BTC"}})
+
+			var freezes, unfreezes int
+			m.OnFreeze(func(MMPFrozenEvent) { freezes++ })
+			m.OnUnfreeze(func(string) { unfreezes++ })
+
+			if tt.fromOrders {
+				m.Handle(mmpOrderMessage(map[string]string{"BTC-27DEC24-60000-C": CancelTypeMMP}))
+			}
+			for _, poll := range tt.polls {
+				source["BTC"] = poll["BTC"]
+				m.Check()
+			}
+
+			if m.Frozen("BTC") != tt.frozen {
+				t.Errorf("frozen = %v, want %v", m.Frozen("BTC"), tt.frozen)
+			}
+			if freezes != 1 {
+				t.Errorf("freeze callbacks = %d, want 1", freezes)
+			}
+			if unfreezes != tt.unfreezes {
+				t.Errorf("unfreeze callbacks = %d, want %d", unfreezes, tt.unfreezes)
+			}
+		})
+	}
+}
+
+func TestMMPMonitorOrderFreezeExpires(t *testing.T) {
+	m := NewMMPMonitor(fakeMMPSource{}, MMPMonitorConfig{BaseCoins: []string{"BTC"}})
+	m.Handle(mmpOrderMessage(map[string]string{"BTC-27DEC24-60000-C": CancelTypeMMP}))
+	at := m.frozen["BTC"].at
+
+	state := &MMPState{BaseCoin: "BTC", FrozenPeriod: "60000"}
+	if m.thawed("BTC", state, at.Add(30*time.Second)) {
+		t.Error("thawed within the frozen period")
+	}
+	if !m.thawed("BTC", state, at.Add(61*time.Second)) {
+		t.Error("still frozen after the frozen period")
+	}
+	if m.thawed("BTC", &MMPState{BaseCoin: "BTC", FrozenPeriod: "0"}, at.Add(time.Hour)) {
+		t.Error("thawed a freeze that lasts until reset")
+	}
+}
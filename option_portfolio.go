@@ -0,0 +1,253 @@
+package bybit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// optionDeliveryHour is the UTC hour at which Bybit options expire.
+const optionDeliveryHour = 8
+
+// OptionPosition is an item of the positions of category option.
+type OptionPosition struct {
+	Symbol        string `json:"symbol"`
+	Side          string `json:"side"`
+	Size          string `json:"size"`
+	AvgPrice      string `json:"avgPrice"`
+	MarkPrice     string `json:"markPrice"`
+	PositionValue string `json:"positionValue"`
+	UnrealisedPnl string `json:"unrealisedPnl"`
+	UpdatedTime   string `json:"updatedTime"`
+}
+
+// OptionPositions returns every open option position of a base coin.
+func (c *Client) OptionPositions(baseCoin string) ([]OptionPosition, error) {
+	params := map[string]interface{}{
+		"category": CategoryOption,
+		"baseCoin": baseCoin,
+		"limit":    200,
+	}
+
+	var positions []OptionPosition
+	for {
+		res, err := c.GetPositions(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []OptionPosition
+		cursor, err := decodeList(res, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page {
+			if size, _ := strconv.ParseFloat(p.Size, 64); size != 0 {
+				positions = append(positions, p)
+			}
+		}
+
+		if cursor == "" || len(page) == 0 {
+			return positions, nil
+		}
+		params["cursor"] = cursor
+	}
+}
+
+// PricedPosition is an option position valued with Black-76.
+type PricedPosition struct {
+	Position OptionPosition
+	Type     string
+	Strike   float64
+	Expiry   time.Time
+	// Qty is the position size, negative for a short position.
+	Qty        float64
+	Underlying float64
+	MarkPrice  float64
+	// IV is solved from MarkPrice, falling back to the exchange mark IV.
+	IV float64
+	// Greeks are for the whole position, i.e. per contract times Qty.
+	Greeks OptionGreeks
+}
+
+// OptionPortfolio is a set of option positions with aggregated greeks.
+type OptionPortfolio struct {
+	Positions []PricedPosition
+	Greeks    OptionGreeks
+	// Rate is the risk-free rate used for pricing, 0 by default.
+	Rate float64
+	// Now is the valuation time.
+	Now time.Time
+}
+
+// Scenario is a market shock. SpotShift is relative, 0.1 moving every
+// underlying up 10%; VolShift is absolute, 0.05 adding 5 volatility points
+// to every IV.
+type Scenario struct {
+	SpotShift float64
+	VolShift  float64
+}
+
+// ScenarioResult is the portfolio revalued under a Scenario.
+type ScenarioResult struct {
+	Scenario
+	Value  float64
+	PnL    float64
+	Greeks OptionGreeks
+}
+
+// OptionPortfolio fetches the option positions and tickers of a base coin
+// and values them at the current time.
+func (c *Client) OptionPortfolio(baseCoin string) (*OptionPortfolio, error) {
+	positions, err := c.OptionPositions(baseCoin)
+	if err != nil {
+		return nil, fmt.Errorf("option positions: %w", err)
+	}
+
+	tickers, err := c.OptionTickers(baseCoin)
+	if err != nil {
+		return nil, fmt.Errorf("option tickers: %w", err)
+	}
+
+	return NewOptionPortfolio(positions, tickers, time.Now(), 0)
+}
+
+// NewOptionPortfolio values positions using the underlying and mark prices
+// of tickers. Every position needs a ticker.
+func NewOptionPortfolio(positions []OptionPosition, tickers []OptionTicker, now time.Time, rate float64) (*OptionPortfolio, error) {
+	bySymbol := make(map[string]OptionTicker, len(tickers))
+	for _, t := range tickers {
+		bySymbol[t.Symbol] = t
+	}
+
+	p := &OptionPortfolio{Rate: rate, Now: now}
+	for _, pos := range positions {
+		ticker, ok := bySymbol[pos.Symbol]
+		if !ok {
+			return nil, fmt.Errorf("no ticker for %s", pos.Symbol)
+		}
+
+		priced, err := pricePosition(pos, ticker, now, rate)
+		if err != nil {
+			return nil, err
+		}
+		p.Positions = append(p.Positions, priced)
+		p.Greeks = p.Greeks.Add(priced.Greeks)
+	}
+	return p, nil
+}
+
+func pricePosition(pos OptionPosition, ticker OptionTicker, now time.Time, rate float64) (PricedPosition, error) {
+	optionType, strike, expiry, err := parseOptionSymbol(pos.Symbol)
+	if err != nil {
+		return PricedPosition{}, err
+	}
+
+	size, err := strconv.ParseFloat(pos.Size, 64)
+	if err != nil {
+		return PricedPosition{}, fmt.Errorf("invalid size %q of %s: %w", pos.Size, pos.Symbol, err)
+	}
+	if pos.Side == "Sell" {
+		size = -size
+	}
+
+	underlying, err := strconv.ParseFloat(ticker.UnderlyingPrice, 64)
+	if err != nil || underlying <= 0 {
+		return PricedPosition{}, fmt.Errorf("no underlying price for %s", pos.Symbol)
+	}
+	mark, _ := strconv.ParseFloat(ticker.MarkPrice, 64)
+
+	t := yearsTo(expiry, now)
+	iv, err := ImpliedVol(optionType, mark, underlying, strike, t, rate)
+	if err != nil || iv == 0 {
+		iv, _ = strconv.ParseFloat(ticker.MarkIv, 64)
+	}
+
+	return PricedPosition{
+		Position:   pos,
+		Type:       optionType,
+		Strike:     strike,
+		Expiry:     expiry,
+		Qty:        size,
+		Underlying: underlying,
+		MarkPrice:  mark,
+		IV:         iv,
+		Greeks:     Black76Greeks(optionType, underlying, strike, t, iv, rate).Scale(size),
+	}, nil
+}
+
+// Value returns the mark value of the portfolio, negative for net short.
+func (p *OptionPortfolio) Value() float64 {
+	value := 0.0
+	for _, pos := range p.Positions {
+		value += pos.Qty * Black76(pos.Type, pos.Underlying, pos.Strike, yearsTo(pos.Expiry, p.Now), pos.IV, p.Rate)
+	}
+	return value
+}
+
+// Shock revalues the portfolio under s, keeping the valuation time.
+func (p *OptionPortfolio) Shock(s Scenario) ScenarioResult {
+	result := ScenarioResult{Scenario: s}
+	t := func(expiry time.Time) float64 { return yearsTo(expiry, p.Now) }
+
+	for _, pos := range p.Positions {
+		underlying := pos.Underlying * (1 + s.SpotShift)
+		vol := pos.IV + s.VolShift
+		if vol < 0 {
+			vol = 0
+		}
+
+		result.Value += pos.Qty * Black76(pos.Type, underlying, pos.Strike, t(pos.Expiry), vol, p.Rate)
+		result.Greeks = result.Greeks.Add(Black76Greeks(pos.Type, underlying, pos.Strike, t(pos.Expiry), vol, p.Rate).Scale(pos.Qty))
+	}
+	result.PnL = result.Value - p.Value()
+	return result
+}
+
+// ShockGrid revalues the portfolio for every combination of spot and vol
+// shifts, spot-major.
+func (p *OptionPortfolio) ShockGrid(spotShifts, volShifts []float64) []ScenarioResult {
+	results := make([]ScenarioResult, 0, len(spotShifts)*len(volShifts))
+	for _, spot := range spotShifts {
+		for _, vol := range volShifts {
+			results = append(results, p.Shock(Scenario{SpotShift: spot, VolShift: vol}))
+		}
+	}
+	return results
+}
+
+// parseOptionSymbol parses the type, strike and expiry of an option symbol
+// such as BTC-27DEC24-60000-C or BTC-27DEC24-60000-C-USDT.
+func parseOptionSymbol(symbol string) (string, float64, time.Time, error) {
+	parts := strings.Split(symbol, "-")
+	if len(parts) < 4 {
+		return "", 0, time.Time{}, fmt.Errorf("invalid option symbol %q", symbol)
+	}
+
+	strike, err := optionStrike(symbol)
+	if err != nil {
+		return "", 0, time.Time{}, err
+	}
+
+	date, err := time.Parse("2Jan06", parts[1])
+	if err != nil {
+		return "", 0, time.Time{}, fmt.Errorf("invalid option symbol %q: %w", symbol, err)
+	}
+	expiry := date.Add(optionDeliveryHour * time.Hour)
+
+	optionType := OptionTypeCall
+	if parts[3] == "P" {
+		optionType = OptionTypePut
+	}
+	return optionType, strike, expiry, nil
+}
+
+// yearsTo returns the time from now to expiry in years, 0 once expired.
+func yearsTo(expiry, now time.Time) float64 {
+	t := expiry.Sub(now).Hours() / 24 / 365
+	if t < 0 {
+		return 0
+	}
+	return t
+}
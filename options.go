@@ -0,0 +1,295 @@
+package bybit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Option types, as in Instrument.OptionsType.
+const (
+	OptionTypeCall = "Call"
+	OptionTypePut  = "Put"
+)
+
+// OptionTicker is an item of the option tickers. IVs are annualised
+// fractions; greeks are per contract.
+type OptionTicker struct {
+	Symbol                 string `json:"symbol"`
+	Bid1Price              string `json:"bid1Price"`
+	Bid1Size               string `json:"bid1Size"`
+	Bid1Iv                 string `json:"bid1Iv"`
+	Ask1Price              string `json:"ask1Price"`
+	Ask1Size               string `json:"ask1Size"`
+	Ask1Iv                 string `json:"ask1Iv"`
+	LastPrice              string `json:"lastPrice"`
+	HighPrice24h           string `json:"highPrice24h"`
+	LowPrice24h            string `json:"lowPrice24h"`
+	MarkPrice              string `json:"markPrice"`
+	IndexPrice             string `json:"indexPrice"`
+	MarkIv                 string `json:"markIv"`
+	UnderlyingPrice        string `json:"underlyingPrice"`
+	OpenInterest           string `json:"openInterest"`
+	Turnover24h            string `json:"turnover24h"`
+	Volume24h              string `json:"volume24h"`
+	TotalVolume            string `json:"totalVolume"`
+	TotalTurnover          string `json:"totalTurnover"`
+	Delta                  string `json:"delta"`
+	Gamma                  string `json:"gamma"`
+	Vega                   string `json:"vega"`
+	Theta                  string `json:"theta"`
+	PredictedDeliveryPrice string `json:"predictedDeliveryPrice"`
+	Change24h              string `json:"change24h"`
+}
+
+// OptionTickers returns the tickers of every option of a base coin.
+func (c *Client) OptionTickers(baseCoin string) ([]OptionTicker, error) {
+	res, err := c.GetTickers(map[string]interface{}{
+		"category": CategoryOption,
+		"baseCoin": baseCoin,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var list []OptionTicker
+	_, err = decodeList(res, &list)
+	return list, err
+}
+
+// OptionContract is a single option of a chain.
+type OptionContract struct {
+	Instrument Instrument
+	// Ticker is nil when the option has no ticker yet.
+	Ticker *OptionTicker
+	Type   string
+	Strike float64
+	Expiry time.Time
+	// Underlying is the underlying price of the option's expiry.
+	Underlying float64
+}
+
+// DaysToExpiry returns the time left until expiry in days, fractional.
+func (o *OptionContract) DaysToExpiry(now time.Time) float64 {
+	return o.Expiry.Sub(now).Hours() / 24
+}
+
+// Moneyness returns strike divided by underlying price: below 1 a call is
+// in the money and a put out of the money. It returns 0 without an
+// underlying price.
+func (o *OptionContract) Moneyness() float64 {
+	if o.Underlying == 0 {
+		return 0
+	}
+	return o.Strike / o.Underlying
+}
+
+// OptionStrike holds the call and put of one strike side by side. Either
+// may be nil when Bybit lists only one of them.
+type OptionStrike struct {
+	Strike float64
+	Call   *OptionContract
+	Put    *OptionContract
+}
+
+// OptionExpiry holds the strikes of one expiry and settle coin, lowest
+// first. Bybit lists USDC and USDT settled options of the same expiry and
+// strike as separate contracts, so each settle coin has its own expiry.
+type OptionExpiry struct {
+	Expiry     time.Time
+	SettleCoin string
+	Underlying float64
+	Strikes    []OptionStrike
+}
+
+// OptionChain is the option chain of a base coin, nearest expiry first and
+// ordered by settle coin within an expiry.
+type OptionChain struct {
+	BaseCoin string
+	Expiries []OptionExpiry
+}
+
+// OptionChainFilter selects part of a chain. Zero bounds are ignored.
+type OptionChainFilter struct {
+	MinDaysToExpiry float64
+	MaxDaysToExpiry float64
+	// MinMoneyness and MaxMoneyness bound strike / underlying, e.g. 0.9
+	// and 1.1 keep strikes within 10% of the underlying.
+	MinMoneyness float64
+	MaxMoneyness float64
+}
+
+// OptionChain fetches the trading option instruments and tickers of a base
+// coin and assembles them into a chain.
+func (c *Client) OptionChain(baseCoin string) (*OptionChain, error) {
+	instruments, err := c.Instruments(InstrumentsQuery{
+		Category: CategoryOption,
+		BaseCoin: baseCoin,
+		Status:   InstrumentStatusTrading,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("option instruments: %w", err)
+	}
+
+	tickers, err := c.OptionTickers(baseCoin)
+	if err != nil {
+		return nil, fmt.Errorf("option tickers: %w", err)
+	}
+
+	return BuildOptionChain(baseCoin, instruments, tickers)
+}
+
+// BuildOptionChain assembles option instruments and tickers into a chain.
+func BuildOptionChain(baseCoin string, instruments []Instrument, tickers []OptionTicker) (*OptionChain, error) {
+	bySymbol := make(map[string]*OptionTicker, len(tickers))
+	for i := range tickers {
+		bySymbol[tickers[i].Symbol] = &tickers[i]
+	}
+
+	type expiryKey struct {
+		expiry     int64
+		settleCoin string
+	}
+	type strikeKey struct {
+		expiryKey
+		strike float64
+	}
+	expiries := make(map[expiryKey]*OptionExpiry)
+	strikes := make(map[strikeKey]*OptionStrike)
+
+	for _, inst := range instruments {
+		strike, err := optionStrike(inst.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		expiryMs := toInt64(inst.DeliveryTime)
+
+		contract := &OptionContract{
+			Instrument: inst,
+			Ticker:     bySymbol[inst.Symbol],
+			Type:       inst.OptionsType,
+			Strike:     strike,
+			Expiry:     time.UnixMilli(expiryMs).UTC(),
+		}
+		if contract.Ticker != nil {
+			contract.Underlying, _ = strconv.ParseFloat(contract.Ticker.UnderlyingPrice, 64)
+		}
+
+		ek := expiryKey{expiryMs, inst.SettleCoin}
+		expiry, ok := expiries[ek]
+		if !ok {
+			expiry = &OptionExpiry{Expiry: contract.Expiry, SettleCoin: inst.SettleCoin}
+			expiries[ek] = expiry
+		}
+		if expiry.Underlying == 0 {
+			expiry.Underlying = contract.Underlying
+		}
+
+		key := strikeKey{ek, strike}
+		s, ok := strikes[key]
+		if !ok {
+			s = &OptionStrike{Strike: strike}
+			strikes[key] = s
+		}
+		if contract.Type == OptionTypePut {
+			s.Put = contract
+		} else {
+			s.Call = contract
+		}
+	}
+
+	for key, s := range strikes {
+		expiry := expiries[key.expiryKey]
+		expiry.Strikes = append(expiry.Strikes, *s)
+	}
+
+	chain := &OptionChain{BaseCoin: baseCoin}
+	for _, expiry := range expiries {
+		for i := range expiry.Strikes {
+			for _, contract := range []*OptionContract{expiry.Strikes[i].Call, expiry.Strikes[i].Put} {
+				if contract != nil && contract.Underlying == 0 {
+					contract.Underlying = expiry.Underlying
+				}
+			}
+		}
+		sort.Slice(expiry.Strikes, func(i, j int) bool { return expiry.Strikes[i].Strike < expiry.Strikes[j].Strike })
+		chain.Expiries = append(chain.Expiries, *expiry)
+	}
+	sort.Slice(chain.Expiries, func(i, j int) bool {
+		a, b := chain.Expiries[i], chain.Expiries[j]
+		if !a.Expiry.Equal(b.Expiry) {
+			return a.Expiry.Before(b.Expiry)
+		}
+		return a.SettleCoin < b.SettleCoin
+	})
+
+	return chain, nil
+}
+
+// Filter returns the expiries and strikes of the chain matching f, with
+// days to expiry measured from now. Expiries left without strikes are
+// dropped.
+func (ch *OptionChain) Filter(f OptionChainFilter, now time.Time) *OptionChain {
+	out := &OptionChain{BaseCoin: ch.BaseCoin}
+
+	for _, expiry := range ch.Expiries {
+		dte := expiry.Expiry.Sub(now).Hours() / 24
+		if f.MinDaysToExpiry != 0 && dte < f.MinDaysToExpiry {
+			continue
+		}
+		if f.MaxDaysToExpiry != 0 && dte > f.MaxDaysToExpiry {
+			continue
+		}
+
+		kept := OptionExpiry{Expiry: expiry.Expiry, SettleCoin: expiry.SettleCoin, Underlying: expiry.Underlying}
+		for _, s := range expiry.Strikes {
+			if expiry.Underlying > 0 {
+				m := s.Strike / expiry.Underlying
+				if f.MinMoneyness != 0 && m < f.MinMoneyness {
+					continue
+				}
+				if f.MaxMoneyness != 0 && m > f.MaxMoneyness {
+					continue
+				}
+			}
+			kept.Strikes = append(kept.Strikes, s)
+		}
+		if len(kept.Strikes) > 0 {
+			out.Expiries = append(out.Expiries, kept)
+		}
+	}
+
+	return out
+}
+
+// Contracts returns every call and put of the chain.
+func (ch *OptionChain) Contracts() []*OptionContract {
+	var contracts []*OptionContract
+	for _, expiry := range ch.Expiries {
+		for _, s := range expiry.Strikes {
+			if s.Call != nil {
+				contracts = append(contracts, s.Call)
+			}
+			if s.Put != nil {
+				contracts = append(contracts, s.Put)
+			}
+		}
+	}
+	return contracts
+}
+
+// optionStrike parses the strike of an option symbol such as
+// BTC-27DEC24-60000-C or BTC-27DEC24-60000-C-USDT.
+func optionStrike(symbol string) (float64, error) {
+	parts := strings.Split(symbol, "-")
+	if len(parts) < 4 {
+		return 0, fmt.Errorf("invalid option symbol %q", symbol)
+	}
+	strike, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid option symbol %q: %w", symbol, err)
+	}
+	return strike, nil
+}
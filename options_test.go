@@ -0,0 +1,178 @@
+package bybit
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+var (
+	optionExpiry1 = time.Date(2024, 12, 27, 8, 0, 0, 0, time.UTC)
+	optionExpiry2 = time.Date(2025, 3, 28, 8, 0, 0, 0, time.UTC)
+)
+
+func optionInstrument(symbol, optionsType, settleCoin string, expiry time.Time) Instrument {
+	return Instrument{
+		Symbol:       symbol,
+		OptionsType:  optionsType,
+		SettleCoin:   settleCoin,
+		DeliveryTime: strconv.FormatInt(expiry.UnixMilli(), 10),
+	}
+}
+
+func TestBuildOptionChain(t *testing.T) {
+	type strike struct {
+		strike    float64
+		call, put bool
+	}
+	type expiry struct {
+		expiry     time.Time
+		settleCoin string
+		underlying float64
+		strikes    []strike
+	}
+
+	tests := []struct {
+		name        string
+		instruments []Instrument
+		tickers     []OptionTicker
+		want        []expiry
+	}{
+		{
+			name: "calls and puts paired by strike",
+			instruments: []Instrument{
+				optionInstrument("BTC-27DEC24-70000-C", OptionTypeCall, "USDC", optionExpiry1),
+				optionInstrument("BTC-27DEC24-60000-P", OptionTypePut, "USDC", optionExpiry1),
+				optionInstrument("BTC-27DEC24-60000-C", OptionTypeCall, "USDC", optionExpiry1),
+			},
+			tickers: []OptionTicker{
+				{Symbol: "BTC-27DEC24-60000-C", UnderlyingPrice: "65000"},
+			},
+			want: []expiry{
+				{optionExpiry1, "USDC", 65000, []strike{{60000, true, true}, {70000, true, false}}},
+			},
+		},
+		{
+			name: "expiries nearest first",
+			instruments: []Instrument{
+				optionInstrument("BTC-28MAR25-60000-C", OptionTypeCall, "USDC", optionExpiry2),
+				optionInstrument("BTC-27DEC24-60000-C", OptionTypeCall, "USDC", optionExpiry1),
+			},
+			tickers: []OptionTicker{
+				{Symbol: "BTC-28MAR25-60000-C", UnderlyingPrice: "66000"},
+				{Symbol: "BTC-27DEC24-60000-C", UnderlyingPrice: "65000"},
+			},
+			want: []expiry{
+				{optionExpiry1, "USDC", 65000, []strike{{60000, true, false}}},
+				{optionExpiry2, "USDC", 66000, []strike{{60000, true, false}}},
+			},
+		},
+		{
+			name: "settle coins split an expiry",
+			instruments: []Instrument{
+				optionInstrument("BTC-27DEC24-60000-C-USDT", OptionTypeCall, "USDT", optionExpiry1),
+				optionInstrument("BTC-27DEC24-60000-C", OptionTypeCall, "USDC", optionExpiry1),
+				optionInstrument("BTC-27DEC24-60000-P-USDT", OptionTypePut, "USDT", optionExpiry1),
+			},
+			tickers: []OptionTicker{
+				{Symbol: "BTC-27DEC24-60000-C", UnderlyingPrice: "65000"},
+				{Symbol: "BTC-27DEC24-60000-P-USDT", UnderlyingPrice: "65010"},
+			},
+			want: []expiry{
+				{optionExpiry1, "USDC", 65000, []strike{{60000, true, false}}},
+				{optionExpiry1, "USDT", 65010, []strike{{60000, true, true}}},
+			},
+		},
+		{
+			name: "no tickers",
+			instruments: []Instrument{
+				optionInstrument("BTC-27DEC24-60000-P", OptionTypePut, "USDC", optionExpiry1),
+			},
+			want: []expiry{
+				{optionExpiry1, "USDC", 0, []strike{{60000, false, true}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := BuildOptionChain("BTC", tt.instruments, tt.tickers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(chain.Expiries) != len(tt.want) {
+				t.Fatalf("expiries = %d, want %d", len(chain.Expiries), len(tt.want))
+			}
+
+			for i, want := range tt.want {
+				got := chain.Expiries[i]
+				if !got.Expiry.Equal(want.expiry) || got.SettleCoin != want.settleCoin || got.Underlying != want.underlying {
+					t.Errorf("expiry %d = %v %s %v, want %v %s %v", i, got.Expiry, got.SettleCoin, got.Underlying, want.expiry, want.settleCoin, want.underlying)
+				}
+				if len(got.Strikes) != len(want.strikes) {
+					t.Fatalf("expiry %d has %d strikes, want %d", i, len(got.Strikes), len(want.strikes))
+				}
+				for j, ws := range want.strikes {
+					s := got.Strikes[j]
+					if s.Strike != ws.strike || (s.Call != nil) != ws.call || (s.Put != nil) != ws.put {
+						t.Errorf("expiry %d strike %d = %v call %v put %v, want %+v", i, j, s.Strike, s.Call != nil, s.Put != nil, ws)
+					}
+					for _, c := range []*OptionContract{s.Call, s.Put} {
+						if c != nil && (c.Instrument.SettleCoin != want.settleCoin || c.Underlying != want.underlying) {
+							t.Errorf("contract %s: settle coin %s, underlying %v", c.Instrument.Symbol, c.Instrument.SettleCoin, c.Underlying)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBuildOptionChainInvalidSymbol(t *testing.T) {
+	_, err := BuildOptionChain("BTC", []Instrument{optionInstrument("BTC-PERP", OptionTypeCall, "USDC", optionExpiry1)}, nil)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestOptionChainFilter(t *testing.T) {
+	chain, err := BuildOptionChain("BTC", []Instrument{
+		optionInstrument("BTC-27DEC24-50000-C", OptionTypeCall, "USDC", optionExpiry1),
+		optionInstrument("BTC-27DEC24-60000-C", OptionTypeCall, "USDC", optionExpiry1),
+		optionInstrument("BTC-27DEC24-70000-C", OptionTypeCall, "USDC", optionExpiry1),
+		optionInstrument("BTC-28MAR25-60000-C", OptionTypeCall, "USDC", optionExpiry2),
+	}, []OptionTicker{
+		{Symbol: "BTC-27DEC24-60000-C", UnderlyingPrice: "60000"},
+		{Symbol: "BTC-28MAR25-60000-C", UnderlyingPrice: "60000"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := optionExpiry1.Add(-10 * 24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		filter  OptionChainFilter
+		strikes []int
+	}{
+		{"no filter", OptionChainFilter{}, []int{3, 1}},
+		{"max days", OptionChainFilter{MaxDaysToExpiry: 30}, []int{3}},
+		{"min days", OptionChainFilter{MinDaysToExpiry: 30}, []int{1}},
+		{"moneyness", OptionChainFilter{MinMoneyness: 0.9, MaxMoneyness: 1.1}, []int{1, 1}},
+		{"nothing left", OptionChainFilter{MinMoneyness: 2}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := chain.Filter(tt.filter, now)
+			if len(got.Expiries) != len(tt.strikes) {
+				t.Fatalf("expiries = %d, want %d", len(got.Expiries), len(tt.strikes))
+			}
+			for i, n := range tt.strikes {
+				if len(got.Expiries[i].Strikes) != n {
+					t.Errorf("expiry %d has %d strikes, want %d", i, len(got.Expiries[i].Strikes), n)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package bybit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Order book sides used by the OrderBook query helpers.
+const (
+	SideBid = "Buy"
+	SideAsk = "Sell"
+)
+
+// PriceLevel is a single aggregated price level of an order book.
+type PriceLevel struct {
+	Price float64
+	Size  float64
+}
+
+// bookState holds the local copy of one symbol's order book.
+// Bids are sorted by descending price, asks by ascending price.
+type bookState struct {
+	topic    string
+	bids     []PriceLevel
+	asks     []PriceLevel
+	updateID int64
+	seq      int64
+	ts       int64
+	synced   bool
+}
+
+// OrderBook maintains sorted in-memory order books from the
+// orderbook.{depth}.{symbol} stream. Feed every message received from
+// WebSocket.OnMessage into Handle; messages for other topics are ignored.
+//
+// Books are keyed by symbol, so subscribe to a single depth per symbol.
+// All query methods are safe to call from other goroutines.
+type OrderBook struct {
+	ws       *WebSocket
+	books    map[string]*bookState
+	onResync func(symbol string, err error)
+	mu       sync.RWMutex
+}
+
+// NewOrderBook creates an empty order book manager. When ws is not nil the
+// orderbook topic of a symbol is re-subscribed after a sequence gap so the
+// server sends a fresh snapshot.
+func NewOrderBook(ws *WebSocket) *OrderBook {
+	return &OrderBook{
+		ws:    ws,
+		books: make(map[string]*bookState),
+	}
+}
+
+// OnResync registers a callback fired whenever a symbol's book is dropped
+// because of a sequence gap, with a nil error, on the goroutine that called
+// Handle. If re-subscribing for a new snapshot then fails, it fires again
+// from another goroutine with the error; the book stays unsynced until the
+// caller subscribes again.
+func (ob *OrderBook) OnResync(callback func(symbol string, err error)) {
+	ob.mu.Lock()
+	ob.onResync = callback
+	ob.mu.Unlock()
+}
+
+// Handle applies an orderbook snapshot or delta message. It returns an
+// error when the message is malformed or a sequence gap was detected.
+func (ob *OrderBook) Handle(message map[string]interface{}) error {
+	topic, _ := message["topic"].(string)
+	// RPI books carry three values per level and are not handled here.
+	if !strings.HasPrefix(topic, "orderbook.") || strings.HasPrefix(topic, "orderbook.rpi.") {
+		return nil
+	}
+
+	data, ok := message["data"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid orderbook message for topic %s", topic)
+	}
+
+	symbol, _ := data["s"].(string)
+	if symbol == "" {
+		return fmt.Errorf("orderbook message without symbol for topic %s", topic)
+	}
+
+	bids, err := parseLevels(data["b"])
+	if err != nil {
+		return err
+	}
+	asks, err := parseLevels(data["a"])
+	if err != nil {
+		return err
+	}
+
+	updateID := toInt64(data["u"])
+	seq := toInt64(data["seq"])
+	ts := toInt64(message["ts"])
+	msgType, _ := message["type"].(string)
+
+	ob.mu.Lock()
+	book, exists := ob.books[symbol]
+	if !exists {
+		book = &bookState{}
+		ob.books[symbol] = book
+	}
+	book.topic = topic
+
+	// A delta with u=1 means the server restarted the stream and the
+	// payload is a full snapshot.
+	if msgType == "snapshot" || updateID == 1 {
+		book.bids = sortLevels(bids, true)
+		book.asks = sortLevels(asks, false)
+		book.updateID = updateID
+		book.seq = seq
+		book.ts = ts
+		book.synced = true
+		ob.mu.Unlock()
+		return nil
+	}
+
+	if !book.synced {
+		ob.mu.Unlock()
+		return nil
+	}
+
+	if updateID != book.updateID+1 {
+		expected := book.updateID + 1
+		book.synced = false
+		book.bids = nil
+		book.asks = nil
+		callback := ob.onResync
+		ob.mu.Unlock()
+
+		ob.resync(symbol, topic, callback)
+		return fmt.Errorf("orderbook gap for %s: expected update %d, got %d", symbol, expected, updateID)
+	}
+
+	for _, level := range bids {
+		book.bids = applyLevel(book.bids, level, true)
+	}
+	for _, level := range asks {
+		book.asks = applyLevel(book.asks, level, false)
+	}
+	book.updateID = updateID
+	book.seq = seq
+	book.ts = ts
+	ob.mu.Unlock()
+
+	return nil
+}
+
+func (ob *OrderBook) resync(symbol, topic string, callback func(string, error)) {
+	if callback != nil {
+		callback(symbol, nil)
+	}
+
+	if ob.ws == nil {
+		return
+	}
+
+	// Re-subscribing makes Bybit push a new snapshot. It runs on its own
+	// goroutine because Handle is usually called from the Listen loop.
+	go func() {
+		err := ob.ws.Unsubscribe([]string{topic})
+		if err == nil {
+			err = ob.ws.Subscribe([]string{topic})
+		}
+		if err != nil && callback != nil {
+			callback(symbol, fmt.Errorf("resubscribe %s: %w", topic, err))
+		}
+	}()
+}
+
+// Reset drops the local book of a symbol. The next snapshot rebuilds it.
+func (ob *OrderBook) Reset(symbol string) {
+	ob.mu.Lock()
+	delete(ob.books, symbol)
+	ob.mu.Unlock()
+}
+
+// Symbols returns the symbols with a synced book, leaving out books that
+// wait for a snapshot after a sequence gap.
+func (ob *OrderBook) Symbols() []string {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	symbols := make([]string, 0, len(ob.books))
+	for symbol, book := range ob.books {
+		if book.synced {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// IsSynced reports whether the book of a symbol is built from a snapshot
+// and has not seen a sequence gap since.
+func (ob *OrderBook) IsSynced(symbol string) bool {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	book, ok := ob.books[symbol]
+	return ok && book.synced
+}
+
+// UpdateID returns the last applied update id, cross sequence and server
+// timestamp of a symbol's book.
+func (ob *OrderBook) UpdateID(symbol string) (updateID, seq, ts int64) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	if book, ok := ob.books[symbol]; ok {
+		return book.updateID, book.seq, book.ts
+	}
+	return 0, 0, 0
+}
+
+// BestBid returns the highest bid of a symbol.
+func (ob *OrderBook) BestBid(symbol string) (PriceLevel, bool) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	book, ok := ob.books[symbol]
+	if !ok || !book.synced || len(book.bids) == 0 {
+		return PriceLevel{}, false
+	}
+	return book.bids[0], true
+}
+
+// BestAsk returns the lowest ask of a symbol.
+func (ob *OrderBook) BestAsk(symbol string) (PriceLevel, bool) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	book, ok := ob.books[symbol]
+	if !ok || !book.synced || len(book.asks) == 0 {
+		return PriceLevel{}, false
+	}
+	return book.asks[0], true
+}
+
+// Snapshot returns copies of the top depth levels of each side.
+// Pass depth <= 0 to get the full book.
+func (ob *OrderBook) Snapshot(symbol string, depth int) (bids, asks []PriceLevel) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	book, ok := ob.books[symbol]
+	if !ok || !book.synced {
+		return nil, nil
+	}
+	return copyLevels(book.bids, depth), copyLevels(book.asks, depth)
+}
+
+// DepthAt returns the resting size at an exact price on the given side
+// (SideBid or SideAsk), or 0 when the level is empty.
+func (ob *OrderBook) DepthAt(symbol, side string, price float64) float64 {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	book, ok := ob.books[symbol]
+	if !ok || !book.synced {
+		return 0
+	}
+
+	levels, desc := book.asks, false
+	if side == SideBid {
+		levels, desc = book.bids, true
+	}
+
+	i := searchLevel(levels, price, desc)
+	if i < len(levels) && levels[i].Price == price {
+		return levels[i].Size
+	}
+	return 0
+}
+
+// Mid returns the midpoint between the best bid and best ask.
+func (ob *OrderBook) Mid(symbol string) (float64, bool) {
+	bid, ask, ok := ob.top(symbol)
+	if !ok {
+		return 0, false
+	}
+	return (bid.Price + ask.Price) / 2, true
+}
+
+// Microprice returns the size-weighted mid price of the top of book,
+// which leans towards the side with less resting size.
+func (ob *OrderBook) Microprice(symbol string) (float64, bool) {
+	bid, ask, ok := ob.top(symbol)
+	if !ok {
+		return 0, false
+	}
+
+	total := bid.Size + ask.Size
+	if total == 0 {
+		return (bid.Price + ask.Price) / 2, true
+	}
+	return (bid.Price*ask.Size + ask.Price*bid.Size) / total, true
+}
+
+// VWAP returns the average fill price of a market order of the given size.
+// side is the taker side: "Buy" walks the asks and "Sell" walks the bids.
+// It returns an error when the book does not hold enough liquidity.
+func (ob *OrderBook) VWAP(symbol, side string, size float64) (float64, error) {
+	if size <= 0 {
+		return 0, fmt.Errorf("size must be positive")
+	}
+
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	book, ok := ob.books[symbol]
+	if !ok || !book.synced {
+		return 0, fmt.Errorf("no synced orderbook for %s", symbol)
+	}
+
+	levels := book.asks
+	if side == SideAsk {
+		levels = book.bids
+	}
+
+	remaining := size
+	notional := 0.0
+	for _, level := range levels {
+		fill := level.Size
+		if fill > remaining {
+			fill = remaining
+		}
+		notional += fill * level.Price
+		remaining -= fill
+		if remaining <= 0 {
+			return notional / size, nil
+		}
+	}
+
+	return 0, fmt.Errorf("insufficient liquidity for %s: %.8f of %.8f filled", symbol, size-remaining, size)
+}
+
+func (ob *OrderBook) top(symbol string) (PriceLevel, PriceLevel, bool) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	book, ok := ob.books[symbol]
+	if !ok || !book.synced || len(book.bids) == 0 || len(book.asks) == 0 {
+		return PriceLevel{}, PriceLevel{}, false
+	}
+	return book.bids[0], book.asks[0], true
+}
+
+// parseLevels converts [["price","size"], ...] into price levels.
+func parseLevels(raw interface{}) ([]PriceLevel, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid orderbook levels")
+	}
+
+	levels := make([]PriceLevel, 0, len(list))
+	for _, item := range list {
+		pair, ok := item.([]interface{})
+		if !ok || len(pair) < 2 {
+			return nil, fmt.Errorf("invalid orderbook level")
+		}
+		priceStr, _ := pair[0].(string)
+		sizeStr, _ := pair[1].(string)
+
+		price, err := strconv.ParseFloat(priceStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid orderbook price %q: %w", priceStr, err)
+		}
+		size, err := strconv.ParseFloat(sizeStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid orderbook size %q: %w", sizeStr, err)
+		}
+		levels = append(levels, PriceLevel{Price: price, Size: size})
+	}
+
+	return levels, nil
+}
+
+func sortLevels(levels []PriceLevel, desc bool) []PriceLevel {
+	out := make([]PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		if level.Size > 0 {
+			out = append(out, level)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if desc {
+			return out[i].Price > out[j].Price
+		}
+		return out[i].Price < out[j].Price
+	})
+	return out
+}
+
+func searchLevel(levels []PriceLevel, price float64, desc bool) int {
+	return sort.Search(len(levels), func(i int) bool {
+		if desc {
+			return levels[i].Price <= price
+		}
+		return levels[i].Price >= price
+	})
+}
+
+// applyLevel inserts, updates or removes (size 0) a level, keeping order.
+func applyLevel(levels []PriceLevel, level PriceLevel, desc bool) []PriceLevel {
+	i := searchLevel(levels, level.Price, desc)
+	found := i < len(levels) && levels[i].Price == level.Price
+
+	switch {
+	case level.Size == 0 && found:
+		return append(levels[:i], levels[i+1:]...)
+	case level.Size == 0:
+		return levels
+	case found:
+		levels[i].Size = level.Size
+		return levels
+	}
+
+	levels = append(levels, PriceLevel{})
+	copy(levels[i+1:], levels[i:])
+	levels[i] = level
+	return levels
+}
+
+func copyLevels(levels []PriceLevel, depth int) []PriceLevel {
+	if depth <= 0 || depth > len(levels) {
+		depth = len(levels)
+	}
+	out := make([]PriceLevel, depth)
+	copy(out, levels[:depth])
+	return out
+}
+
+// toInt64 converts a JSON number or numeric string into an int64.
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case float64:
+		return int64(n)
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case string:
+		i, _ := strconv.ParseInt(n, 10, 64)
+		return i
+	}
+	return 0
+}
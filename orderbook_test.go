@@ -0,0 +1,199 @@
+package bybit
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func bookMessage(t *testing.T, raw string) map[string]interface{} {
+	t.Helper()
+	var message map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &message); err != nil {
+		t.Fatal(err)
+	}
+	return message
+}
+
+func TestOrderBookSequence(t *testing.T) {
+	const snapshot = `{"topic":"orderbook.50.BTCUSDT","type":"snapshot","ts":1,"data":{"s":"BTCUSDT","b":[["100","1"],["99","2"]],"a":[["101","1"],["102","2"]],"u":10,"seq":1}}`
+
+	tests := []struct {
+		name     string
+		messages []string
+		wantErr  []bool
+		synced   bool
+		updateID int64
+		bestBid  PriceLevel
+		bestAsk  PriceLevel
+		resyncs  int
+	}{
+		{
+			name:     "snapshot",
+			messages: []string{snapshot},
+			wantErr:  []bool{false},
+			synced:   true,
+			updateID: 10,
+			bestBid:  PriceLevel{100, 1},
+			bestAsk:  PriceLevel{101, 1},
+		},
+		{
+			name: "contiguous delta updates and removes levels",
+			messages: []string{
+				snapshot,
+				`{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":2,"data":{"s":"BTCUSDT","b":[["100","0"],["99.5","3"]],"a":[["100.5","4"]],"u":11,"seq":2}}`,
+			},
+			wantErr:  []bool{false, false},
+			synced:   true,
+			updateID: 11,
+			bestBid:  PriceLevel{99.5, 3},
+			bestAsk:  PriceLevel{100.5, 4},
+		},
+		{
+			name: "gap drops the book",
+			messages: []string{
+				snapshot,
+				`{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":2,"data":{"s":"BTCUSDT","b":[["100","5"]],"a":[],"u":12,"seq":2}}`,
+			},
+			wantErr: []bool{false, true},
+			synced:  false,
+			resyncs: 1,
+		},
+		{
+			name: "deltas ignored until the next snapshot",
+			messages: []string{
+				snapshot,
+				`{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":2,"data":{"s":"BTCUSDT","b":[],"a":[],"u":12,"seq":2}}`,
+				`{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":3,"data":{"s":"BTCUSDT","b":[],"a":[],"u":13,"seq":3}}`,
+				snapshot,
+			},
+			wantErr:  []bool{false, true, false, false},
+			synced:   true,
+			updateID: 10,
+			bestBid:  PriceLevel{100, 1},
+			bestAsk:  PriceLevel{101, 1},
+			resyncs:  1,
+		},
+		{
+			name: "delta with u=1 is a snapshot",
+			messages: []string{
+				snapshot,
+				`{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":2,"data":{"s":"BTCUSDT","b":[["90","1"]],"a":[["91","1"]],"u":1,"seq":5}}`,
+			},
+			wantErr:  []bool{false, false},
+			synced:   true,
+			updateID: 1,
+			bestBid:  PriceLevel{90, 1},
+			bestAsk:  PriceLevel{91, 1},
+		},
+		{
+			name: "delta before any snapshot",
+			messages: []string{
+				`{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":2,"data":{"s":"BTCUSDT","b":[["100","1"]],"a":[],"u":11,"seq":2}}`,
+			},
+			wantErr: []bool{false},
+			synced:  false,
+		},
+		{
+			name: "rpi and other topics ignored",
+			messages: []string{
+				`{"topic":"orderbook.rpi.BTCUSDT","type":"snapshot","data":{"s":"BTCUSDT","b":[["100","1","0"]],"a":[],"u":1}}`,
+				`{"topic":"tickers.BTCUSDT","type":"snapshot","data":{"symbol":"BTCUSDT"}}`,
+			},
+			wantErr: []bool{false, false},
+			synced:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ob := NewOrderBook(nil)
+			resyncs := 0
+			ob.OnResync(func(string, error) { resyncs++ })
+
+			for i, raw := range tt.messages {
+				err := ob.Handle(bookMessage(t, raw))
+				if (err != nil) != tt.wantErr[i] {
+					t.Fatalf("message %d: err = %v, want error %v", i, err, tt.wantErr[i])
+				}
+			}
+
+			if got := ob.IsSynced("BTCUSDT"); got != tt.synced {
+				t.Fatalf("synced = %v, want %v", got, tt.synced)
+			}
+			if resyncs != tt.resyncs {
+				t.Errorf("resyncs = %d, want %d", resyncs, tt.resyncs)
+			}
+			if !tt.synced {
+				return
+			}
+			if updateID, _, _ := ob.UpdateID("BTCUSDT"); updateID != tt.updateID {
+				t.Errorf("updateID = %d, want %d", updateID, tt.updateID)
+			}
+			if bid, _ := ob.BestBid("BTCUSDT"); bid != tt.bestBid {
+				t.Errorf("best bid = %+v, want %+v", bid, tt.bestBid)
+			}
+			if ask, _ := ob.BestAsk("BTCUSDT"); ask != tt.bestAsk {
+				t.Errorf("best ask = %+v, want %+v", ask, tt.bestAsk)
+			}
+		})
+	}
+}
+
+func TestOrderBookResyncError(t *testing.T) {
+	srv := fakeStream(t, func(conn *websocket.Conn, msg map[string]interface{}) {
+		conn.WriteJSON(map[string]interface{}{
+			"op":      msg["op"],
+			"req_id":  msg["req_id"],
+			"success": false,
+			"ret_msg": "error:handler not found",
+		})
+	})
+	ws := fakeWebSocket(srv, WebSocketConfig{})
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	ob := NewOrderBook(ws)
+	type resync struct {
+		symbol string
+		err    error
+	}
+	resyncs := make(chan resync, 2)
+	ob.OnResync(func(symbol string, err error) { resyncs <- resync{symbol, err} })
+
+	for _, raw := range []string{
+		`{"topic":"orderbook.50.BTCUSDT","type":"snapshot","data":{"s":"BTCUSDT","b":[["100","1"]],"a":[["101","1"]],"u":10}}`,
+		`{"topic":"orderbook.50.ETHUSDT","type":"snapshot","data":{"s":"ETHUSDT","b":[["10","1"]],"a":[["11","1"]],"u":10}}`,
+	} {
+		if err := ob.Handle(bookMessage(t, raw)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := ob.Symbols(); !reflect.DeepEqual(got, []string{"BTCUSDT", "ETHUSDT"}) {
+		t.Errorf("symbols = %v", got)
+	}
+
+	gap := `{"topic":"orderbook.50.BTCUSDT","type":"delta","data":{"s":"BTCUSDT","b":[],"a":[],"u":12}}`
+	if err := ob.Handle(bookMessage(t, gap)); err == nil {
+		t.Fatal("expected a gap error")
+	}
+	if got := ob.Symbols(); !reflect.DeepEqual(got, []string{"ETHUSDT"}) {
+		t.Errorf("symbols after gap = %v, want [ETHUSDT]", got)
+	}
+
+	for i, wantErr := range []bool{false, true} {
+		select {
+		case r := <-resyncs:
+			if r.symbol != "BTCUSDT" || (r.err != nil) != wantErr {
+				t.Errorf("resync %d = %s %v, want error %v", i, r.symbol, r.err, wantErr)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("resync %d not reported", i)
+		}
+	}
+}
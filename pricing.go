@@ -0,0 +1,216 @@
+package bybit
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrNoImpliedVol is returned by the IV solvers when the price lies outside
+// the no-arbitrage bounds of the option, so no volatility reproduces it.
+var ErrNoImpliedVol = errors.New("price outside no-arbitrage bounds")
+
+// OptionGreeks are the greeks of an option. Delta and Gamma are with
+// respect to the underlying price, Vega is per volatility point (0.01) and
+// Theta per calendar day, the conventions Bybit uses for the greeks it
+// publishes.
+type OptionGreeks struct {
+	Delta float64
+	Gamma float64
+	Vega  float64
+	Theta float64
+}
+
+// Add returns the sum of two sets of greeks.
+func (g OptionGreeks) Add(o OptionGreeks) OptionGreeks {
+	return OptionGreeks{
+		Delta: g.Delta + o.Delta,
+		Gamma: g.Gamma + o.Gamma,
+		Vega:  g.Vega + o.Vega,
+		Theta: g.Theta + o.Theta,
+	}
+}
+
+// Scale returns the greeks multiplied by qty, e.g. a signed position size.
+func (g OptionGreeks) Scale(qty float64) OptionGreeks {
+	return OptionGreeks{
+		Delta: g.Delta * qty,
+		Gamma: g.Gamma * qty,
+		Vega:  g.Vega * qty,
+		Theta: g.Theta * qty,
+	}
+}
+
+// Black76 prices a European option on a forward or futures price F with
+// strike K, t years to expiry, volatility vol and risk-free rate r, all
+// annualised. optionType is OptionTypeCall or OptionTypePut. Bybit options
+// settle on the expiry's underlying price, so Black-76 with
+// OptionTicker.UnderlyingPrice as F and r = 0 matches the exchange mark.
+func Black76(optionType string, F, K, t, vol, r float64) float64 {
+	df := math.Exp(-r * t)
+	if t <= 0 || vol <= 0 {
+		return df * intrinsic(optionType, F, K)
+	}
+
+	d1, d2 := blackD(F, K, t, vol)
+	if optionType == OptionTypePut {
+		return df * (K*normCDF(-d2) - F*normCDF(-d1))
+	}
+	return df * (F*normCDF(d1) - K*normCDF(d2))
+}
+
+// Black76Greeks returns the greeks of a Black-76 option, delta and gamma
+// being with respect to F.
+func Black76Greeks(optionType string, F, K, t, vol, r float64) OptionGreeks {
+	df := math.Exp(-r * t)
+	if t <= 0 || vol <= 0 {
+		return OptionGreeks{Delta: df * intrinsicDelta(optionType, F, K)}
+	}
+
+	d1, _ := blackD(F, K, t, vol)
+	sqrtT := math.Sqrt(t)
+	pdf := normPDF(d1)
+	price := Black76(optionType, F, K, t, vol, r)
+
+	g := OptionGreeks{
+		Gamma: df * pdf / (F * vol * sqrtT),
+		Vega:  df * F * pdf * sqrtT / 100,
+		Theta: (-df*F*pdf*vol/(2*sqrtT) + r*price) / 365,
+	}
+	if optionType == OptionTypePut {
+		g.Delta = -df * normCDF(-d1)
+	} else {
+		g.Delta = df * normCDF(d1)
+	}
+	return g
+}
+
+// BlackScholes prices a European option on a spot price S paying a
+// continuous yield q.
+func BlackScholes(optionType string, S, K, t, vol, r, q float64) float64 {
+	return Black76(optionType, S*math.Exp((r-q)*t), K, t, vol, r)
+}
+
+// BlackScholesGreeks returns the greeks of a Black-Scholes option, delta
+// and gamma being with respect to S.
+func BlackScholesGreeks(optionType string, S, K, t, vol, r, q float64) OptionGreeks {
+	qf := math.Exp(-q * t)
+	if t <= 0 || vol <= 0 {
+		return OptionGreeks{Delta: intrinsicDelta(optionType, S, K)}
+	}
+
+	F := S * math.Exp((r-q)*t)
+	d1, d2 := blackD(F, K, t, vol)
+	sqrtT := math.Sqrt(t)
+	pdf := normPDF(d1)
+	rf := math.Exp(-r * t)
+
+	g := OptionGreeks{
+		Gamma: qf * pdf / (S * vol * sqrtT),
+		Vega:  S * qf * pdf * sqrtT / 100,
+	}
+	decay := -S * qf * pdf * vol / (2 * sqrtT)
+	if optionType == OptionTypePut {
+		g.Delta = -qf * normCDF(-d1)
+		g.Theta = (decay + r*K*rf*normCDF(-d2) - q*S*qf*normCDF(-d1)) / 365
+	} else {
+		g.Delta = qf * normCDF(d1)
+		g.Theta = (decay - r*K*rf*normCDF(d2) + q*S*qf*normCDF(d1)) / 365
+	}
+	return g
+}
+
+// ImpliedVol solves the Black-76 volatility that reproduces price. It
+// combines Newton steps with bisection, so it converges for deep in- and
+// out-of-the-money options where Newton alone diverges.
+func ImpliedVol(optionType string, price, F, K, t, r float64) (float64, error) {
+	if t <= 0 || F <= 0 || K <= 0 {
+		return 0, errors.New("implied vol needs positive forward, strike and time")
+	}
+
+	df := math.Exp(-r * t)
+	lower := df * intrinsic(optionType, F, K)
+	upper := df * F
+	if optionType == OptionTypePut {
+		upper = df * K
+	}
+	if price < lower || price >= upper {
+		return 0, ErrNoImpliedVol
+	}
+	if price == lower {
+		return 0, nil
+	}
+
+	const (
+		tolerance = 1e-10
+		maxIter   = 200
+	)
+
+	lo, hi := 1e-6, 5.0
+	for Black76(optionType, F, K, t, hi, r) < price {
+		hi *= 2
+		if hi > 100 {
+			return 0, ErrNoImpliedVol
+		}
+	}
+
+	// Start near the Brenner-Subrahmanyam approximation.
+	vol := math.Sqrt(2*math.Pi/t) * price / (df * F)
+	if vol <= lo || vol >= hi {
+		vol = (lo + hi) / 2
+	}
+
+	for i := 0; i < maxIter; i++ {
+		diff := Black76(optionType, F, K, t, vol, r) - price
+		if math.Abs(diff) < tolerance {
+			return vol, nil
+		}
+		if diff > 0 {
+			hi = vol
+		} else {
+			lo = vol
+		}
+
+		vega := Black76Greeks(optionType, F, K, t, vol, r).Vega * 100
+		next := vol - diff/vega
+		if vega <= 1e-12 || next <= lo || next >= hi {
+			next = (lo + hi) / 2
+		}
+		if hi-lo < tolerance {
+			return next, nil
+		}
+		vol = next
+	}
+
+	return vol, nil
+}
+
+func blackD(F, K, t, vol float64) (float64, float64) {
+	sd := vol * math.Sqrt(t)
+	d1 := (math.Log(F/K) + 0.5*sd*sd) / sd
+	return d1, d1 - sd
+}
+
+func intrinsic(optionType string, F, K float64) float64 {
+	if optionType == OptionTypePut {
+		return math.Max(K-F, 0)
+	}
+	return math.Max(F-K, 0)
+}
+
+func intrinsicDelta(optionType string, F, K float64) float64 {
+	switch {
+	case optionType == OptionTypePut && F < K:
+		return -1
+	case optionType != OptionTypePut && F > K:
+		return 1
+	}
+	return 0
+}
+
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+func normPDF(x float64) float64 {
+	return math.Exp(-0.5*x*x) / math.Sqrt(2*math.Pi)
+}
@@ -0,0 +1,145 @@
+package bybit
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBlackPrices(t *testing.T) {
+	tests := []struct {
+		name  string
+		price float64
+		want  float64
+		tol   float64
+	}{
+		// ATM Black-76 with r = 0: F * (2N(sigma*sqrt(t)/2) - 1).
+		{"black76 atm call", Black76(OptionTypeCall, 100, 100, 1, 0.2, 0), 7.965567455405804, 1e-9},
+		{"black76 atm put", Black76(OptionTypePut, 100, 100, 1, 0.2, 0), 7.965567455405804, 1e-9},
+		// Hull, Options, Futures and Other Derivatives, example 15.6.
+		{"black-scholes call", BlackScholes(OptionTypeCall, 42, 40, 0.5, 0.2, 0.1, 0), 4.7594, 1e-4},
+		{"black-scholes put", BlackScholes(OptionTypePut, 42, 40, 0.5, 0.2, 0.1, 0), 0.8086, 1e-4},
+		{"expired call is intrinsic", Black76(OptionTypeCall, 110, 100, 0, 0.5, 0), 10, 0},
+		{"expired put is intrinsic", Black76(OptionTypePut, 110, 100, 0, 0.5, 0), 0, 0},
+		{"zero vol is discounted intrinsic", Black76(OptionTypePut, 90, 100, 1, 0, 0.05), 10 * math.Exp(-0.05), 1e-12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if math.Abs(tt.price-tt.want) > tt.tol {
+				t.Errorf("price = %.10f, want %.10f", tt.price, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlack76PutCallParity(t *testing.T) {
+	for _, K := range []float64{50, 90, 100, 110, 200} {
+		F, tt, vol, r := 100.0, 0.75, 0.6, 0.03
+		call := Black76(OptionTypeCall, F, K, tt, vol, r)
+		put := Black76(OptionTypePut, F, K, tt, vol, r)
+		if want := math.Exp(-r*tt) * (F - K); math.Abs(call-put-want) > 1e-9 {
+			t.Errorf("K=%v: call - put = %v, want %v", K, call-put, want)
+		}
+	}
+}
+
+func TestBlack76GreeksMatchFiniteDifferences(t *testing.T) {
+	const F, K, tt, vol, r = 65000.0, 70000.0, 30.0 / 365, 0.55, 0.02
+
+	for _, optionType := range []string{OptionTypeCall, OptionTypePut} {
+		t.Run(optionType, func(t *testing.T) {
+			price := func(F, t, vol float64) float64 { return Black76(optionType, F, K, t, vol, r) }
+			g := Black76Greeks(optionType, F, K, tt, vol, r)
+
+			hF, hV, hT := 1.0, 1e-4, 1e-5
+			delta := (price(F+hF, tt, vol) - price(F-hF, tt, vol)) / (2 * hF)
+			gamma := (price(F+hF, tt, vol) - 2*price(F, tt, vol) + price(F-hF, tt, vol)) / (hF * hF)
+			vega := (price(F, tt, vol+hV) - price(F, tt, vol-hV)) / (2 * hV) / 100
+			theta := -(price(F, tt+hT, vol) - price(F, tt-hT, vol)) / (2 * hT) / 365
+
+			checks := []struct {
+				name      string
+				got, want float64
+			}{
+				{"delta", g.Delta, delta},
+				{"gamma", g.Gamma, gamma},
+				{"vega", g.Vega, vega},
+				{"theta", g.Theta, theta},
+			}
+			for _, c := range checks {
+				if math.Abs(c.got-c.want) > 1e-4*math.Max(1, math.Abs(c.want)) {
+					t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestImpliedVolRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		optionType string
+		F, K, t    float64
+		vol, r     float64
+	}{
+		{"atm call", OptionTypeCall, 100, 100, 0.5, 0.5, 0},
+		{"deep itm call", OptionTypeCall, 100, 40, 0.25, 0.6, 0},
+		{"deep otm call", OptionTypeCall, 100, 250, 0.25, 0.8, 0},
+		{"deep otm put", OptionTypePut, 100, 30, 0.1, 1.2, 0},
+		{"itm put with rate", OptionTypePut, 100, 130, 1, 0.4, 0.05},
+		{"short dated", OptionTypeCall, 65000, 66000, 1.0 / 365, 0.45, 0},
+		{"high vol", OptionTypeCall, 100, 100, 1, 3.5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := Black76(tt.optionType, tt.F, tt.K, tt.t, tt.vol, tt.r)
+			vol, err := ImpliedVol(tt.optionType, price, tt.F, tt.K, tt.t, tt.r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if repriced := Black76(tt.optionType, tt.F, tt.K, tt.t, vol, tt.r); math.Abs(repriced-price) > 1e-8*math.Max(1, price) {
+				t.Errorf("vol = %v reprices to %v, want %v (vol %v)", vol, repriced, price, tt.vol)
+			}
+		})
+	}
+}
+
+func TestImpliedVolBounds(t *testing.T) {
+	tests := []struct {
+		name       string
+		optionType string
+		price      float64
+		F, K, t    float64
+		want       float64
+		err        error
+		anyErr     bool
+	}{
+		{name: "below intrinsic", optionType: OptionTypeCall, price: 5, F: 110, K: 100, t: 1, err: ErrNoImpliedVol},
+		{name: "call above forward", optionType: OptionTypeCall, price: 100, F: 100, K: 90, t: 1, err: ErrNoImpliedVol},
+		{name: "put above strike", optionType: OptionTypePut, price: 120, F: 100, K: 120, t: 1, err: ErrNoImpliedVol},
+		{name: "intrinsic is zero vol", optionType: OptionTypeCall, price: 10, F: 110, K: 100, t: 1, want: 0},
+		{name: "expired", optionType: OptionTypeCall, price: 1, F: 100, K: 100, t: 0, anyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vol, err := ImpliedVol(tt.optionType, tt.price, tt.F, tt.K, tt.t, 0)
+			switch {
+			case tt.anyErr:
+				if err == nil {
+					t.Error("expected an error")
+				}
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Errorf("err = %v, want %v", err, tt.err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case vol != tt.want:
+				t.Errorf("vol = %v, want %v", vol, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package bybit
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// APIError is returned by the typed helpers when Bybit answers with a
+// non-zero retCode.
+type APIError struct {
+	Code    int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("bybit api error %d: %s", e.Code, e.Message)
+}
+
+// checkResponse returns the result object of a REST response, or an
+// *APIError when retCode is not zero.
+func checkResponse(res map[string]interface{}) (map[string]interface{}, error) {
+	if res == nil {
+		return nil, fmt.Errorf("empty response")
+	}
+	if raw, ok := res["raw"].(string); ok {
+		return nil, fmt.Errorf("invalid response: %s", raw)
+	}
+
+	code, _ := res["retCode"].(float64)
+	if code != 0 {
+		msg, _ := res["retMsg"].(string)
+		return nil, &APIError{Code: int(code), Message: msg}
+	}
+
+	result, ok := res["result"].(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+	return result, nil
+}
+
+// resultList returns result.list and result.nextPageCursor of a paginated
+// REST response.
+func resultList(res map[string]interface{}) ([]interface{}, string, error) {
+	result, err := checkResponse(res)
+	if err != nil {
+		return nil, "", err
+	}
+
+	list, _ := result["list"].([]interface{})
+	cursor, _ := result["nextPageCursor"].(string)
+	return list, cursor, nil
+}
+
+// decodeResult decodes the result object of a REST response into v.
+func decodeResult(res map[string]interface{}, v interface{}) error {
+	result, err := checkResponse(res)
+	if err != nil {
+		return err
+	}
+	return decodeValue(result, v)
+}
+
+// decodeList decodes result.list of a paginated REST response into v, a
+// pointer to a slice, and returns result.nextPageCursor.
+func decodeList(res map[string]interface{}, v interface{}) (string, error) {
+	list, cursor, err := resultList(res)
+	if err != nil {
+		return "", err
+	}
+	if list == nil {
+		list = []interface{}{}
+	}
+	return cursor, decodeValue(list, v)
+}
+
+// decodeRows decodes result.rows, used instead of result.list by the
+// asset endpoints, into v and returns result.nextPageCursor.
+func decodeRows(res map[string]interface{}, v interface{}) (string, error) {
+	result, err := checkResponse(res)
+	if err != nil {
+		return "", err
+	}
+
+	rows, ok := result["rows"].([]interface{})
+	if !ok {
+		rows = []interface{}{}
+	}
+	cursor, _ := result["nextPageCursor"].(string)
+	return cursor, decodeValue(rows, v)
+}
+
+// decodeValue converts a decoded JSON value such as a map or slice into v.
+func decodeValue(value interface{}, v interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
@@ -0,0 +1,129 @@
+package bybit
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func decodeJSON(t *testing.T, raw string) map[string]interface{} {
+	t.Helper()
+	var res map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+type decodeItem struct {
+	Coin   string `json:"coin"`
+	Amount string `json:"amount"`
+}
+
+func TestDecodeListAndRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		decode  func(map[string]interface{}, interface{}) (string, error)
+		res     string
+		items   []decodeItem
+		cursor  string
+		apiCode int
+		wantErr bool
+	}{
+		{
+			name:   "list with cursor",
+			decode: decodeList,
+			res:    `{"retCode":0,"retMsg":"OK","result":{"list":[{"coin":"BTC","amount":"1"},{"coin":"ETH","amount":"2"}],"nextPageCursor":"abc"}}`,
+			items:  []decodeItem{{"BTC", "1"}, {"ETH", "2"}},
+			cursor: "abc",
+		},
+		{
+			name:   "empty list",
+			decode: decodeList,
+			res:    `{"retCode":0,"retMsg":"OK","result":{"list":[],"nextPageCursor":""}}`,
+			items:  []decodeItem{},
+		},
+		{
+			name:   "missing list",
+			decode: decodeList,
+			res:    `{"retCode":0,"retMsg":"OK","result":{}}`,
+			items:  []decodeItem{},
+		},
+		{
+			name:    "list api error",
+			decode:  decodeList,
+			res:     `{"retCode":10001,"retMsg":"params error","result":{}}`,
+			apiCode: 10001,
+		},
+		{
+			name:   "rows with cursor",
+			decode: decodeRows,
+			res:    `{"retCode":0,"retMsg":"success","result":{"rows":[{"coin":"USDT","amount":"10"}],"nextPageCursor":"next"}}`,
+			items:  []decodeItem{{"USDT", "10"}},
+			cursor: "next",
+		},
+		{
+			name:   "rows ignore list",
+			decode: decodeRows,
+			res:    `{"retCode":0,"retMsg":"success","result":{"list":[{"coin":"BTC"}]}}`,
+			items:  []decodeItem{},
+		},
+		{
+			name:    "rows api error",
+			decode:  decodeRows,
+			res:     `{"retCode":131001,"retMsg":"server error","result":{}}`,
+			apiCode: 131001,
+		},
+		{
+			name:    "rows of the wrong type",
+			decode:  decodeRows,
+			res:     `{"retCode":0,"retMsg":"success","result":{"rows":[{"coin":1}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "raw body",
+			decode:  decodeList,
+			res:     `{"raw":"<html>bad gateway</html>"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var items []decodeItem
+			cursor, err := tt.decode(decodeJSON(t, tt.res), &items)
+
+			if tt.apiCode != 0 {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.Code != tt.apiCode {
+					t.Fatalf("err = %v, want api error %d", err, tt.apiCode)
+				}
+				return
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cursor != tt.cursor {
+				t.Errorf("cursor = %q, want %q", cursor, tt.cursor)
+			}
+			if items == nil {
+				t.Fatal("items not set, want an empty slice")
+			}
+			if len(items) != len(tt.items) {
+				t.Fatalf("items = %+v, want %+v", items, tt.items)
+			}
+			for i := range items {
+				if items[i] != tt.items[i] {
+					t.Errorf("item %d = %+v, want %+v", i, items[i], tt.items[i])
+				}
+			}
+		})
+	}
+}
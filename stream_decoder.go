@@ -0,0 +1,344 @@
+package bybit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// maxPooledBuffer is the largest read buffer returned to the pool, so one
+// huge snapshot does not pin memory forever.
+const maxPooledBuffer = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBuffer {
+		bufferPool.Put(buf)
+	}
+}
+
+// PeekTopic returns the top-level topic of a raw stream frame without
+// decoding it, or "" for control frames such as subscribe responses.
+func PeekTopic(raw []byte) string {
+	return peekString(raw, "topic")
+}
+
+// peekString returns the string value of a top-level key of a JSON object.
+func peekString(raw []byte, key string) string {
+	i := peekValue(raw, key)
+	if i < 0 || i >= len(raw) || raw[i] != '"' {
+		return ""
+	}
+	end := bytes.IndexByte(raw[i+1:], '"')
+	if end < 0 {
+		return ""
+	}
+	return string(raw[i+1 : i+1+end])
+}
+
+// peekInt returns the integer value of a top-level key of a JSON object,
+// accepting both JSON numbers and numeric strings.
+func peekInt(raw []byte, key string) int64 {
+	i := peekValue(raw, key)
+	if i < 0 || i >= len(raw) {
+		return 0
+	}
+	if raw[i] == '"' {
+		i++
+	}
+	var n int64
+	for ; i < len(raw) && raw[i] >= '0' && raw[i] <= '9'; i++ {
+		n = n*10 + int64(raw[i]-'0')
+	}
+	return n
+}
+
+// peekValue returns the index of the value of a top-level key of the JSON
+// object raw, or -1. Keys of nested objects are skipped, so a "type" or
+// "topic" inside data is never mistaken for the envelope field. To peek
+// into a nested object, pass the slice starting at its value.
+func peekValue(raw []byte, key string) int {
+	depth := 0
+	for i := 0; i < len(raw); i++ {
+		switch raw[i] {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return -1
+			}
+		case '"':
+			end := stringEnd(raw, i)
+			if end < 0 {
+				return -1
+			}
+			// Only keys are followed by a colon.
+			if depth == 1 && string(raw[i+1:end]) == key {
+				j := skipSpace(raw, end+1)
+				if j < len(raw) && raw[j] == ':' {
+					return skipSpace(raw, j+1)
+				}
+			}
+			i = end
+		}
+	}
+	return -1
+}
+
+// stringEnd returns the index of the quote closing the string starting at
+// raw[start], or -1.
+func stringEnd(raw []byte, start int) int {
+	for i := start + 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func skipSpace(raw []byte, i int) int {
+	for i < len(raw) && (raw[i] == ' ' || raw[i] == '\t' || raw[i] == '\n' || raw[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// StreamHeader holds the envelope fields of a public stream frame.
+type StreamHeader struct {
+	Topic string `json:"topic"`
+	Type  string `json:"type"`
+	Ts    int64  `json:"ts"`
+	Cts   int64  `json:"cts"`
+}
+
+// OrderbookUpdate is the payload of the orderbook.{depth}.{symbol} topic.
+// Each level is [price, size]; size "0" removes the level in a delta.
+type OrderbookUpdate struct {
+	Symbol   string      `json:"s"`
+	Bids     [][2]string `json:"b"`
+	Asks     [][2]string `json:"a"`
+	UpdateID int64       `json:"u"`
+	Seq      int64       `json:"seq"`
+}
+
+// PublicTrade is an item of the publicTrade.{symbol} topic.
+type PublicTrade struct {
+	Time       int64  `json:"T"`
+	Symbol     string `json:"s"`
+	Side       string `json:"S"`
+	Size       string `json:"v"`
+	Price      string `json:"p"`
+	Direction  string `json:"L"`
+	ID         string `json:"i"`
+	BlockTrade bool   `json:"BT"`
+}
+
+// TickerUpdate is the payload of the tickers.{symbol} topic. Spot tickers
+// fill a subset of the fields, and derivatives deltas only carry the fields
+// that changed.
+type TickerUpdate struct {
+	Symbol            string `json:"symbol"`
+	TickDirection     string `json:"tickDirection"`
+	LastPrice         string `json:"lastPrice"`
+	PrevPrice24h      string `json:"prevPrice24h"`
+	Price24hPcnt      string `json:"price24hPcnt"`
+	HighPrice24h      string `json:"highPrice24h"`
+	LowPrice24h       string `json:"lowPrice24h"`
+	PrevPrice1h       string `json:"prevPrice1h"`
+	MarkPrice         string `json:"markPrice"`
+	IndexPrice        string `json:"indexPrice"`
+	USDIndexPrice     string `json:"usdIndexPrice"`
+	OpenInterest      string `json:"openInterest"`
+	OpenInterestValue string `json:"openInterestValue"`
+	Turnover24h       string `json:"turnover24h"`
+	Volume24h         string `json:"volume24h"`
+	NextFundingTime   string `json:"nextFundingTime"`
+	FundingRate       string `json:"fundingRate"`
+	Bid1Price         string `json:"bid1Price"`
+	Bid1Size          string `json:"bid1Size"`
+	Ask1Price         string `json:"ask1Price"`
+	Ask1Size          string `json:"ask1Size"`
+}
+
+// KlineUpdate is an item of the kline.{interval}.{symbol} topic.
+type KlineUpdate struct {
+	Start     int64  `json:"start"`
+	End       int64  `json:"end"`
+	Interval  string `json:"interval"`
+	Open      string `json:"open"`
+	Close     string `json:"close"`
+	High      string `json:"high"`
+	Low       string `json:"low"`
+	Volume    string `json:"volume"`
+	Turnover  string `json:"turnover"`
+	Confirm   bool   `json:"confirm"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type orderbookFrame struct {
+	StreamHeader
+	Data OrderbookUpdate `json:"data"`
+}
+
+type tradeFrame struct {
+	StreamHeader
+	Data []PublicTrade `json:"data"`
+}
+
+type tickerFrame struct {
+	StreamHeader
+	Data TickerUpdate `json:"data"`
+}
+
+type klineFrame struct {
+	StreamHeader
+	Data []KlineUpdate `json:"data"`
+}
+
+// StreamDecoder decodes raw public stream frames straight into typed
+// structs, skipping the generic map used by OnMessage. Frames are pooled
+// and reused, so handlers must not keep the values they receive after
+// returning; copy what you need.
+//
+// Use it with WebSocket.OnRawMessage:
+//
+//	decoder := bybit.NewStreamDecoder()
+//	decoder.OnOrderbook(func(h bybit.StreamHeader, book *bybit.OrderbookUpdate) { ... })
+//	ws.OnRawMessage(func(raw []byte) { decoder.Decode(raw) })
+type StreamDecoder struct {
+	onOrderbook func(StreamHeader, *OrderbookUpdate)
+	onTrade     func(StreamHeader, []PublicTrade)
+	onTicker    func(StreamHeader, *TickerUpdate)
+	onKline     func(StreamHeader, []KlineUpdate)
+	onOther     func([]byte)
+	books       sync.Pool
+	trades      sync.Pool
+	tickers     sync.Pool
+	klines      sync.Pool
+}
+
+// NewStreamDecoder creates a decoder without handlers.
+func NewStreamDecoder() *StreamDecoder {
+	return &StreamDecoder{
+		books:   sync.Pool{New: func() interface{} { return new(orderbookFrame) }},
+		trades:  sync.Pool{New: func() interface{} { return new(tradeFrame) }},
+		tickers: sync.Pool{New: func() interface{} { return new(tickerFrame) }},
+		klines:  sync.Pool{New: func() interface{} { return new(klineFrame) }},
+	}
+}
+
+// OnOrderbook sets the handler for orderbook.{depth}.{symbol} frames.
+func (d *StreamDecoder) OnOrderbook(handler func(StreamHeader, *OrderbookUpdate)) {
+	d.onOrderbook = handler
+}
+
+// OnTrade sets the handler for publicTrade.{symbol} frames.
+func (d *StreamDecoder) OnTrade(handler func(StreamHeader, []PublicTrade)) {
+	d.onTrade = handler
+}
+
+// OnTicker sets the handler for tickers.{symbol} frames.
+func (d *StreamDecoder) OnTicker(handler func(StreamHeader, *TickerUpdate)) {
+	d.onTicker = handler
+}
+
+// OnKline sets the handler for kline.{interval}.{symbol} frames.
+func (d *StreamDecoder) OnKline(handler func(StreamHeader, []KlineUpdate)) {
+	d.onKline = handler
+}
+
+// OnOther sets the handler for every other frame, including control frames
+// and topics without a typed handler.
+func (d *StreamDecoder) OnOther(handler func([]byte)) {
+	d.onOther = handler
+}
+
+// Decode peeks the topic of a frame and dispatches it to the matching
+// handler.
+func (d *StreamDecoder) Decode(raw []byte) error {
+	topic := PeekTopic(raw)
+
+	switch {
+	case d.onOrderbook != nil && strings.HasPrefix(topic, "orderbook.") && !strings.HasPrefix(topic, "orderbook.rpi."):
+		frame := d.books.Get().(*orderbookFrame)
+		bids, asks := frame.Data.Bids[:0], frame.Data.Asks[:0]
+		*frame = orderbookFrame{}
+		frame.Data.Bids, frame.Data.Asks = bids, asks
+
+		err := json.Unmarshal(raw, frame)
+		if err == nil {
+			d.onOrderbook(frame.StreamHeader, &frame.Data)
+		}
+		d.books.Put(frame)
+		return err
+
+	case d.onTrade != nil && strings.HasPrefix(topic, "publicTrade."):
+		frame := d.trades.Get().(*tradeFrame)
+		// json appends into the spare capacity without zeroing it, so
+		// clear the old elements or fields a frame omits would survive.
+		data := frame.Data[:cap(frame.Data)]
+		clear(data)
+		*frame = tradeFrame{Data: data[:0]}
+
+		err := json.Unmarshal(raw, frame)
+		if err == nil {
+			d.onTrade(frame.StreamHeader, frame.Data)
+		}
+		d.trades.Put(frame)
+		return err
+
+	case d.onTicker != nil && strings.HasPrefix(topic, "tickers."):
+		frame := d.tickers.Get().(*tickerFrame)
+		*frame = tickerFrame{}
+
+		err := json.Unmarshal(raw, frame)
+		if err == nil {
+			d.onTicker(frame.StreamHeader, &frame.Data)
+		}
+		d.tickers.Put(frame)
+		return err
+
+	case d.onKline != nil && strings.HasPrefix(topic, "kline."):
+		frame := d.klines.Get().(*klineFrame)
+		// json appends into the spare capacity without zeroing it, so
+		// clear the old elements or fields a frame omits would survive.
+		data := frame.Data[:cap(frame.Data)]
+		clear(data)
+		*frame = klineFrame{Data: data[:0]}
+
+		err := json.Unmarshal(raw, frame)
+		if err == nil {
+			d.onKline(frame.StreamHeader, frame.Data)
+		}
+		d.klines.Put(frame)
+		return err
+	}
+
+	if d.onOther != nil {
+		d.onOther(raw)
+	}
+	return nil
+}
+
+// errorFrame builds the raw form of the error message delivered by Listen.
+func errorFrame(err error) []byte {
+	raw, _ := json.Marshal(map[string]interface{}{
+		"error":   true,
+		"message": err.Error(),
+	})
+	return raw
+}
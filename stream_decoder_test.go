@@ -0,0 +1,191 @@
+package bybit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPeekTopLevelKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   string
+		topic string
+		typ   string
+		ts    int64
+	}{
+		{
+			name:  "envelope first",
+			raw:   `{"topic":"orderbook.50.BTCUSDT","type":"delta","ts":1700000000000,"data":{"s":"BTCUSDT"}}`,
+			topic: "orderbook.50.BTCUSDT",
+			typ:   "delta",
+			ts:    1700000000000,
+		},
+		{
+			name:  "nested keys before envelope",
+			raw:   `{"data":{"topic":"nested","type":"inner","ts":1},"topic":"tickers.BTCUSDT","type":"snapshot","ts":"42"}`,
+			topic: "tickers.BTCUSDT",
+			typ:   "snapshot",
+			ts:    42,
+		},
+		{
+			name:  "key inside array",
+			raw:   `{"data":[{"topic":"nested"}],"ts":7}`,
+			topic: "",
+			ts:    7,
+		},
+		{
+			name:  "key as string value",
+			raw:   `{"op":"topic","args":["topic"]}`,
+			topic: "",
+		},
+		{
+			name:  "escaped quote in value",
+			raw:   `{"ret_msg":"bad \"topic\":\"x\"","topic":"kline.1.BTCUSDT"}`,
+			topic: "kline.1.BTCUSDT",
+		},
+		{
+			name:  "whitespace",
+			raw:   "{ \"topic\" :\n \"publicTrade.BTCUSDT\", \"ts\": 5 }",
+			topic: "publicTrade.BTCUSDT",
+			ts:    5,
+		},
+		{
+			name:  "control frame",
+			raw:   `{"success":true,"ret_msg":"","op":"subscribe","conn_id":"abc"}`,
+			topic: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := []byte(tt.raw)
+			if got := PeekTopic(raw); got != tt.topic {
+				t.Errorf("PeekTopic = %q, want %q", got, tt.topic)
+			}
+			if got := peekString(raw, "type"); got != tt.typ {
+				t.Errorf("type = %q, want %q", got, tt.typ)
+			}
+			if got := peekInt(raw, "ts"); got != tt.ts {
+				t.Errorf("ts = %d, want %d", got, tt.ts)
+			}
+		})
+	}
+}
+
+func TestPeekNestedValue(t *testing.T) {
+	raw := []byte(`{"topic":"orderbook.50.BTCUSDT","u":1,"data":{"s":"BTCUSDT","b":[["1","2"]],"u":18521288}}`)
+
+	i := peekValue(raw, "data")
+	if i < 0 {
+		t.Fatal("data not found")
+	}
+	if got := peekInt(raw[i:], "u"); got != 18521288 {
+		t.Errorf("data.u = %d, want 18521288", got)
+	}
+}
+
+func TestStreamDecoderDispatch(t *testing.T) {
+	decoder := NewStreamDecoder()
+
+	var books, others int
+	decoder.OnOrderbook(func(h StreamHeader, book *OrderbookUpdate) {
+		books++
+		if h.Topic != "orderbook.50.BTCUSDT" || book.UpdateID != 18521288 || len(book.Bids) != 50 {
+			t.Errorf("unexpected book %+v %+v", h, book)
+		}
+	})
+	decoder.OnOther(func([]byte) { others++ })
+
+	if err := decoder.Decode(benchOrderbookFrame(50)); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoder.Decode([]byte(`{"success":true,"op":"subscribe"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if books != 1 || others != 1 {
+		t.Errorf("books = %d, others = %d, want 1 and 1", books, others)
+	}
+}
+
+func TestStreamDecoderClearsReusedFrames(t *testing.T) {
+	decoder := NewStreamDecoder()
+
+	var trades []PublicTrade
+	decoder.OnTrade(func(h StreamHeader, data []PublicTrade) {
+		trades = append(trades, data...)
+	})
+	var klines []KlineUpdate
+	decoder.OnKline(func(h StreamHeader, data []KlineUpdate) {
+		klines = append(klines, data...)
+	})
+
+	frames := []string{
+		`{"topic":"publicTrade.BTCUSDT","type":"snapshot","ts":1,"data":[{"T":1,"s":"BTCUSDT","S":"Buy","v":"1","p":"65000","L":"PlusTick","i":"a","BT":true}]}`,
+		`{"topic":"publicTrade.BTCUSDT","type":"snapshot","ts":2,"data":[{"T":2,"s":"BTCUSDT","S":"Sell","v":"2","p":"64999","i":"b"}]}`,
+		`{"topic":"kline.1.BTCUSDT","type":"snapshot","ts":1,"data":[{"start":0,"end":59999,"interval":"1","close":"65000","confirm":true,"timestamp":1}]}`,
+		`{"topic":"kline.1.BTCUSDT","type":"snapshot","ts":2,"data":[{"start":60000,"end":119999,"interval":"1","close":"65001"}]}`,
+	}
+	for _, frame := range frames {
+		if err := decoder.Decode([]byte(frame)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(trades) != 2 || len(klines) != 2 {
+		t.Fatalf("trades = %d, klines = %d, want 2 and 2", len(trades), len(klines))
+	}
+	if trades[1].BlockTrade || trades[1].Direction != "" {
+		t.Errorf("second trade kept fields of the first: %+v", trades[1])
+	}
+	if klines[1].Confirm || klines[1].Timestamp != 0 {
+		t.Errorf("second kline kept fields of the first: %+v", klines[1])
+	}
+}
+
+// BenchmarkDecodeStream compares decoding an orderbook.50 snapshot into the
+// generic map used by OnMessage with the typed StreamDecoder used with
+// OnRawMessage. Run with -benchmem to compare allocations.
+func BenchmarkDecodeStream(b *testing.B) {
+	frame := benchOrderbookFrame(50)
+
+	b.Run("map", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(frame)))
+		for i := 0; i < b.N; i++ {
+			var data map[string]interface{}
+			if err := json.Unmarshal(frame, &data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("StreamDecoder", func(b *testing.B) {
+		decoder := NewStreamDecoder()
+		levels := 0
+		decoder.OnOrderbook(func(h StreamHeader, book *OrderbookUpdate) {
+			levels += len(book.Bids) + len(book.Asks)
+		})
+
+		b.ReportAllocs()
+		b.SetBytes(int64(len(frame)))
+		for i := 0; i < b.N; i++ {
+			if err := decoder.Decode(frame); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func benchOrderbookFrame(depth int) []byte {
+	var bids, asks []string
+	for i := 0; i < depth; i++ {
+		bids = append(bids, fmt.Sprintf(`["%s","1.234"]`, strconv.FormatFloat(65000-float64(i)*0.1, 'f', 1, 64)))
+		asks = append(asks, fmt.Sprintf(`["%s","0.567"]`, strconv.FormatFloat(65000.1+float64(i)*0.1, 'f', 1, 64)))
+	}
+	return []byte(`{"topic":"orderbook.50.BTCUSDT","type":"snapshot","ts":1700000000000,` +
+		`"data":{"s":"BTCUSDT","b":[` + strings.Join(bids, ",") + `],"a":[` + strings.Join(asks, ",") +
+		`],"u":18521288,"seq":7961638724},"cts":1700000000000}`)
+}
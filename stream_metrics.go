@@ -0,0 +1,284 @@
+package bybit
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// latencySamples is the number of recent latencies kept per topic for
+	// the percentile estimates.
+	latencySamples = 1024
+	// rateWindow is the interval over which message rates are measured.
+	rateWindow = 10 * time.Second
+)
+
+// MetricsHandler receives stream health events as they happen. Methods are
+// called on the read loop goroutine and must not block.
+type MetricsHandler interface {
+	// OnMessageLatency is called for every message carrying a server
+	// timestamp with the delay between that timestamp and receipt.
+	OnMessageLatency(topic string, latency time.Duration)
+	// OnReconnect is called after every connection except the first.
+	OnReconnect(reconnects uint64)
+	// OnPingRTT is called when a pong answers a ping sent by Ping.
+	OnPingRTT(rtt time.Duration)
+	// OnSequenceGap is called when an orderbook delta skips update ids.
+	OnSequenceGap(topic string, expected, got int64)
+}
+
+// TopicMetrics describes the message flow of a single topic.
+type TopicMetrics struct {
+	Messages uint64
+	// Rate is messages per second over the last complete 10s window.
+	Rate        float64
+	LatencyP50  time.Duration
+	LatencyP90  time.Duration
+	LatencyP99  time.Duration
+	LatencyMax  time.Duration
+	Gaps        uint64
+	LastMessage time.Time
+}
+
+// StreamMetrics is a snapshot of a WebSocket's health metrics.
+type StreamMetrics struct {
+	Topics       map[string]TopicMetrics
+	Messages     uint64
+	Connects     uint64
+	Reconnects   uint64
+	PingRTT      time.Duration
+	SequenceGaps uint64
+	// Dropped counts messages discarded because Listen did not keep up
+	// with the connection or was not running.
+	Dropped uint64
+}
+
+type topicStats struct {
+	messages    uint64
+	windowCount uint64
+	windowStart time.Time
+	rate        float64
+	latencies   []time.Duration
+	next        int
+	max         time.Duration
+	gaps        uint64
+	lastUpdate  int64
+	lastMessage time.Time
+}
+
+// streamMetrics collects the metrics of one WebSocket.
+type streamMetrics struct {
+	handler  MetricsHandler
+	topics   map[string]*topicStats
+	messages uint64
+	connects uint64
+	gaps     uint64
+	drops    uint64
+	pingSent time.Time
+	pingRTT  time.Duration
+	mu       sync.Mutex
+}
+
+func newStreamMetrics(handler MetricsHandler) *streamMetrics {
+	return &streamMetrics{
+		handler: handler,
+		topics:  make(map[string]*topicStats),
+	}
+}
+
+func (m *streamMetrics) connected() {
+	m.mu.Lock()
+	m.connects++
+	reconnects := m.connects - 1
+	m.mu.Unlock()
+
+	if reconnects > 0 && m.handler != nil {
+		m.handler.OnReconnect(reconnects)
+	}
+}
+
+func (m *streamMetrics) dropped() {
+	m.mu.Lock()
+	m.drops++
+	m.mu.Unlock()
+}
+
+func (m *streamMetrics) pingSentAt(t time.Time) {
+	m.mu.Lock()
+	m.pingSent = t
+	m.mu.Unlock()
+}
+
+// observe records a received message.
+func (m *streamMetrics) observe(data map[string]interface{}, received time.Time) {
+	op, _ := data["op"].(string)
+	if op == "pong" || (op == "ping" && data["ret_msg"] == "pong") {
+		m.observePong(received)
+		return
+	}
+
+	topic, _ := data["topic"].(string)
+	if topic == "" {
+		return
+	}
+
+	msgType, _ := data["type"].(string)
+	var updateID int64
+	if payload, ok := data["data"].(map[string]interface{}); ok {
+		updateID = toInt64(payload["u"])
+	}
+	m.observeFrame(topic, msgType, serverTimestamp(data), updateID, received)
+}
+
+// observeFrame records a topic message. ts is the server time in
+// milliseconds, updateID the orderbook update id, both 0 when absent.
+func (m *streamMetrics) observeFrame(topic, msgType string, ts, updateID int64, received time.Time) {
+	var latency time.Duration
+	hasLatency := false
+	if ts > 0 {
+		latency = received.Sub(time.UnixMilli(ts))
+		if latency < 0 {
+			latency = 0
+		}
+		hasLatency = true
+	}
+
+	gap, expected, got := false, int64(0), int64(0)
+
+	m.mu.Lock()
+	m.messages++
+	stats, ok := m.topics[topic]
+	if !ok {
+		stats = &topicStats{windowStart: received, latencies: make([]time.Duration, 0, latencySamples)}
+		m.topics[topic] = stats
+	}
+	stats.messages++
+	stats.windowCount++
+	stats.lastMessage = received
+	if elapsed := received.Sub(stats.windowStart); elapsed >= rateWindow {
+		stats.rate = float64(stats.windowCount) / elapsed.Seconds()
+		stats.windowCount = 0
+		stats.windowStart = received
+	}
+
+	if hasLatency {
+		if len(stats.latencies) < latencySamples {
+			stats.latencies = append(stats.latencies, latency)
+		} else {
+			stats.latencies[stats.next] = latency
+			stats.next = (stats.next + 1) % latencySamples
+		}
+		if latency > stats.max {
+			stats.max = latency
+		}
+	}
+
+	if strings.HasPrefix(topic, "orderbook.") && updateID > 0 {
+		if msgType == "delta" && updateID != 1 && stats.lastUpdate > 0 && updateID != stats.lastUpdate+1 {
+			gap, expected, got = true, stats.lastUpdate+1, updateID
+			stats.gaps++
+			m.gaps++
+		}
+		stats.lastUpdate = updateID
+	}
+	m.mu.Unlock()
+
+	if m.handler == nil {
+		return
+	}
+	if hasLatency {
+		m.handler.OnMessageLatency(topic, latency)
+	}
+	if gap {
+		m.handler.OnSequenceGap(topic, expected, got)
+	}
+}
+
+func (m *streamMetrics) observePong(received time.Time) {
+	m.mu.Lock()
+	if m.pingSent.IsZero() {
+		m.mu.Unlock()
+		return
+	}
+	rtt := received.Sub(m.pingSent)
+	m.pingRTT = rtt
+	m.pingSent = time.Time{}
+	m.mu.Unlock()
+
+	if m.handler != nil {
+		m.handler.OnPingRTT(rtt)
+	}
+}
+
+func (m *streamMetrics) snapshot() StreamMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := StreamMetrics{
+		Topics:       make(map[string]TopicMetrics, len(m.topics)),
+		Messages:     m.messages,
+		Connects:     m.connects,
+		PingRTT:      m.pingRTT,
+		SequenceGaps: m.gaps,
+		Dropped:      m.drops,
+	}
+	if m.connects > 0 {
+		out.Reconnects = m.connects - 1
+	}
+
+	for topic, stats := range m.topics {
+		rate := stats.rate
+		if rate == 0 {
+			if elapsed := time.Since(stats.windowStart).Seconds(); elapsed > 0 {
+				rate = float64(stats.windowCount) / elapsed
+			}
+		}
+
+		sorted := make([]time.Duration, len(stats.latencies))
+		copy(sorted, stats.latencies)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		out.Topics[topic] = TopicMetrics{
+			Messages:    stats.messages,
+			Rate:        rate,
+			LatencyP50:  percentile(sorted, 0.50),
+			LatencyP90:  percentile(sorted, 0.90),
+			LatencyP99:  percentile(sorted, 0.99),
+			LatencyMax:  stats.max,
+			Gaps:        stats.gaps,
+			LastMessage: stats.lastMessage,
+		}
+	}
+
+	return out
+}
+
+func (m *streamMetrics) reset() {
+	m.mu.Lock()
+	m.topics = make(map[string]*topicStats)
+	m.messages = 0
+	m.gaps = 0
+	m.drops = 0
+	m.mu.Unlock()
+}
+
+// serverTimestamp returns the server time of a message in milliseconds:
+// ts on public topics, creationTime on private ones, cts as a fallback.
+func serverTimestamp(data map[string]interface{}) int64 {
+	for _, key := range []string{"ts", "creationTime", "cts"} {
+		if ts := toInt64(data[key]); ts > 0 {
+			return ts
+		}
+	}
+	return 0
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted)-1) * p)
+	return sorted[i]
+}
@@ -0,0 +1,128 @@
+package bybit
+
+import (
+	"fmt"
+)
+
+// DecodeStreamData decodes the data field of a stream message received by
+// OnMessage into v, typically one of the payload models below or a slice
+// of them.
+func DecodeStreamData(message map[string]interface{}, v interface{}) error {
+	data, ok := message["data"]
+	if !ok {
+		return fmt.Errorf("stream message has no data")
+	}
+
+	return decodeValue(data, v)
+}
+
+// Liquidation is an item of the allLiquidation.{symbol} topic.
+type Liquidation struct {
+	UpdatedTime int64  `json:"T"`
+	Symbol      string `json:"s"`
+	// Side is the side of the liquidated position: Buy for a long
+	// position, Sell for a short one.
+	Side  string `json:"S"`
+	Size  string `json:"v"`
+	Price string `json:"p"`
+}
+
+// LTKline is an item of the kline_lt.{interval}.{symbol} topic.
+type LTKline struct {
+	Start     int64  `json:"start"`
+	End       int64  `json:"end"`
+	Interval  string `json:"interval"`
+	Open      string `json:"open"`
+	Close     string `json:"close"`
+	High      string `json:"high"`
+	Low       string `json:"low"`
+	Confirm   bool   `json:"confirm"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// LTTicker is the payload of the tickers_lt.{symbol} topic.
+type LTTicker struct {
+	Symbol       string `json:"symbol"`
+	Price24hPcnt string `json:"price24hPcnt"`
+	LastPrice    string `json:"lastPrice"`
+	PrevPrice24h string `json:"prevPrice24h"`
+	HighPrice24h string `json:"highPrice24h"`
+	LowPrice24h  string `json:"lowPrice24h"`
+}
+
+// LTNav is the payload of the lt.{symbol} topic.
+type LTNav struct {
+	Time           int64  `json:"time"`
+	Symbol         string `json:"symbol"`
+	Nav            string `json:"nav"`
+	BasketPosition string `json:"basketPosition"`
+	Leverage       string `json:"leverage"`
+	BasketLoan     string `json:"basketLoan"`
+	Circulation    string `json:"circulation"`
+	Basket         string `json:"basket"`
+}
+
+// RPIOrderbook is the payload of the orderbook.rpi.{symbol} topic. Each
+// level is [price, non-RPI size, RPI size].
+type RPIOrderbook struct {
+	Symbol   string      `json:"s"`
+	Bids     [][3]string `json:"b"`
+	Asks     [][3]string `json:"a"`
+	UpdateID int64       `json:"u"`
+	Seq      int64       `json:"seq"`
+}
+
+// InsurancePool is an item of the insurance.{coin} topic.
+type InsurancePool struct {
+	Coin       string `json:"coin"`
+	Symbols    string `json:"symbols"`
+	Balance    string `json:"balance"`
+	UpdateTime string `json:"updateTime"`
+}
+
+// PriceLimit is the payload of the priceLimit.{symbol} topic.
+type PriceLimit struct {
+	Symbol string `json:"symbol"`
+	// BuyLmt is the highest price a buy order may be placed at.
+	BuyLmt string `json:"buyLmt"`
+	// SellLmt is the lowest price a sell order may be placed at.
+	SellLmt string `json:"sellLmt"`
+}
+
+// Disconnection Protect products, as used in the dcp.{product} topic.
+const (
+	DCPProductFutures = "future"
+	DCPProductSpot    = "spot"
+	DCPProductOptions = "option"
+)
+
+// FastExecution is an item of the execution.fast topic.
+type FastExecution struct {
+	Category    string `json:"category"`
+	Symbol      string `json:"symbol"`
+	ExecID      string `json:"execId"`
+	ExecPrice   string `json:"execPrice"`
+	ExecQty     string `json:"execQty"`
+	OrderID     string `json:"orderId"`
+	IsMaker     bool   `json:"isMaker"`
+	OrderLinkID string `json:"orderLinkId"`
+	Side        string `json:"side"`
+	ExecTime    string `json:"execTime"`
+	Seq         int64  `json:"seq"`
+}
+
+// Greeks is an item of the greeks topic.
+type Greeks struct {
+	BaseCoin   string `json:"baseCoin"`
+	TotalDelta string `json:"totalDelta"`
+	TotalGamma string `json:"totalGamma"`
+	TotalVega  string `json:"totalVega"`
+	TotalTheta string `json:"totalTheta"`
+}
+
+// DCPStatus is an item of the dcp.{product} topic.
+type DCPStatus struct {
+	Product    string `json:"product"`
+	DCPStatus  string `json:"dcpStatus"`
+	TimeWindow int    `json:"timeWindow"`
+}
@@ -0,0 +1,502 @@
+package bybit
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// StreamPoolConfig configures a StreamPool.
+type StreamPoolConfig struct {
+	WebSocketConfig
+	// MaxArgsPerRequest is the number of topics sent in one subscribe
+	// request. Bybit allows 10 for spot. Defaults to 10.
+	MaxArgsPerRequest int
+	// MaxTopicsPerConn is the number of topics held by one connection.
+	// Defaults to 200.
+	MaxTopicsPerConn int
+	// ReconnectDelay is the wait before reconnecting a dropped connection.
+	// Defaults to 2s.
+	ReconnectDelay time.Duration
+}
+
+// StreamPool spreads subscriptions for a large symbol universe over several
+// WebSocket connections. Subscribe requests are batched within the per
+// request argument limit, dropped connections are reconnected and their
+// topics rebalanced, and the messages of all connections are delivered as
+// one stream through OnMessage and Listen.
+type StreamPool struct {
+	config   StreamPoolConfig
+	conns    []*poolConn
+	topics   map[string]*poolConn
+	events   chan map[string]interface{}
+	quit     chan struct{}
+	callback func(map[string]interface{})
+	wg       sync.WaitGroup
+	opMu     sync.Mutex
+	mu       sync.RWMutex
+	closed   bool
+
+	// pending holds topics that lost their connection and could not be
+	// placed again; retryPending keeps trying them while it is non-empty.
+	pending  []string
+	retrying bool
+
+	url string
+}
+
+type poolConn struct {
+	ws     *WebSocket
+	topics []string
+	// down is set while the connection is dropped and waiting to be
+	// reconnected; no topics are assigned to it meanwhile.
+	down bool
+}
+
+// NewStreamPool creates an empty pool. Connections are opened on demand by
+// Subscribe.
+func NewStreamPool(config StreamPoolConfig) *StreamPool {
+	if config.MaxArgsPerRequest <= 0 {
+		config.MaxArgsPerRequest = 10
+	}
+	if config.MaxTopicsPerConn <= 0 {
+		config.MaxTopicsPerConn = 200
+	}
+	if config.ReconnectDelay == 0 {
+		config.ReconnectDelay = 2 * time.Second
+	}
+
+	return &StreamPool{
+		config: config,
+		topics: make(map[string]*poolConn),
+		events: make(chan map[string]interface{}, inboxSize),
+		quit:   make(chan struct{}),
+	}
+}
+
+// OnMessage sets the callback receiving messages from every connection.
+func (p *StreamPool) OnMessage(callback func(map[string]interface{})) {
+	p.mu.Lock()
+	p.callback = callback
+	p.mu.Unlock()
+}
+
+// Listen delivers the merged message stream to the OnMessage callback,
+// one message at a time, until Close is called.
+func (p *StreamPool) Listen() error {
+	for data := range p.events {
+		p.mu.RLock()
+		callback := p.callback
+		p.mu.RUnlock()
+
+		if callback != nil {
+			callback(data)
+		}
+	}
+	return nil
+}
+
+// Subscribe subscribes to topics, placing each on the least loaded
+// connection and opening new connections as needed. Topics that are
+// already subscribed are skipped.
+func (p *StreamPool) Subscribe(topics []string) error {
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return fmt.Errorf("stream pool is closed")
+	}
+	pending := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		if _, ok := p.topics[topic]; !ok && !containsString(pending, topic) {
+			pending = append(pending, topic)
+		}
+	}
+	p.mu.RUnlock()
+
+	return p.assign(pending)
+}
+
+// Unsubscribe removes topics from the connections holding them.
+func (p *StreamPool) Unsubscribe(topics []string) error {
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return fmt.Errorf("stream pool is closed")
+	}
+	groups := make(map[*poolConn][]string)
+	for _, topic := range topics {
+		if pc, ok := p.topics[topic]; ok {
+			groups[pc] = append(groups[pc], topic)
+		}
+	}
+	p.mu.RUnlock()
+
+	for pc, group := range groups {
+		for _, batch := range chunkStrings(group, p.config.MaxArgsPerRequest) {
+			p.mu.RLock()
+			down := pc.down
+			p.mu.RUnlock()
+
+			// A dropped connection holds no subscriptions on the server;
+			// dropping the topics keeps rebalance from restoring them.
+			if !down {
+				if err := pc.ws.Unsubscribe(batch); err != nil {
+					return err
+				}
+			}
+
+			p.mu.Lock()
+			for _, topic := range batch {
+				delete(p.topics, topic)
+				pc.topics = removeString(pc.topics, topic)
+			}
+			p.mu.Unlock()
+		}
+	}
+
+	p.mu.Lock()
+	for _, topic := range topics {
+		p.pending = removeString(p.pending, topic)
+	}
+	p.mu.Unlock()
+
+	return nil
+}
+
+// GetSubscriptions returns all confirmed topics of the pool, sorted.
+func (p *StreamPool) GetSubscriptions() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	topics := make([]string, 0, len(p.topics))
+	for topic := range p.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// ConnectionCount returns the number of connections in the pool.
+func (p *StreamPool) ConnectionCount() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.conns)
+}
+
+// Distribution returns the number of topics held by each connection.
+func (p *StreamPool) Distribution() []int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	counts := make([]int, len(p.conns))
+	for i, pc := range p.conns {
+		counts[i] = len(pc.topics)
+	}
+	return counts
+}
+
+// Close closes every connection and ends Listen.
+func (p *StreamPool) Close() error {
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	conns := p.conns
+	p.mu.Unlock()
+
+	close(p.quit)
+
+	var firstErr error
+	for _, pc := range conns {
+		if err := pc.ws.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	go func() {
+		p.wg.Wait()
+		close(p.events)
+	}()
+
+	return firstErr
+}
+
+// assign distributes topics over the connections and subscribes them in
+// batches. The caller must hold opMu.
+func (p *StreamPool) assign(topics []string) error {
+	groups := make(map[*poolConn][]string)
+	order := make([]*poolConn, 0)
+
+	for _, topic := range topics {
+		pc, err := p.leastLoaded(groups)
+		if err != nil {
+			return err
+		}
+		if _, ok := groups[pc]; !ok {
+			order = append(order, pc)
+		}
+		groups[pc] = append(groups[pc], topic)
+	}
+
+	for _, pc := range order {
+		for _, batch := range chunkStrings(groups[pc], p.config.MaxArgsPerRequest) {
+			if err := pc.ws.Subscribe(batch); err != nil {
+				return err
+			}
+
+			p.mu.Lock()
+			for _, topic := range batch {
+				p.topics[topic] = pc
+				pc.topics = append(pc.topics, topic)
+			}
+			p.mu.Unlock()
+		}
+	}
+
+	return nil
+}
+
+// leastLoaded returns the live connection with the most free capacity,
+// counting topics already planned in groups, and opens a new one when all
+// are full or down.
+func (p *StreamPool) leastLoaded(groups map[*poolConn][]string) (*poolConn, error) {
+	p.mu.RLock()
+	var best *poolConn
+	bestLoad := p.config.MaxTopicsPerConn
+	for _, pc := range p.conns {
+		if pc.down {
+			continue
+		}
+		load := len(pc.topics) + len(groups[pc])
+		if load < bestLoad {
+			best, bestLoad = pc, load
+		}
+	}
+	p.mu.RUnlock()
+
+	if best != nil {
+		return best, nil
+	}
+	return p.openConn()
+}
+
+// openConn opens a connection and adds it to the pool. The caller must
+// hold opMu, which keeps Close from running concurrently.
+func (p *StreamPool) openConn() (*poolConn, error) {
+	p.mu.RLock()
+	closed := p.closed
+	p.mu.RUnlock()
+	if closed {
+		return nil, fmt.Errorf("stream pool is closed")
+	}
+
+	ws := NewWebSocket(p.config.WebSocketConfig)
+	ws.url = p.url
+	ws.OnMessage(p.forward)
+	if err := ws.Connect(); err != nil {
+		return nil, err
+	}
+
+	pc := &poolConn{ws: ws}
+
+	p.mu.Lock()
+	p.conns = append(p.conns, pc)
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go p.run(pc)
+
+	return pc, nil
+}
+
+func (p *StreamPool) forward(data map[string]interface{}) {
+	select {
+	case p.events <- data:
+	case <-p.quit:
+	}
+}
+
+// run listens on a connection and reconnects it when it drops. While it is
+// down the connection is skipped by Subscribe.
+func (p *StreamPool) run(pc *poolConn) {
+	defer p.wg.Done()
+
+	for {
+		if pc.ws.IsConnected() {
+			pc.ws.Listen()
+		}
+
+		p.mu.Lock()
+		pc.down = true
+		p.mu.Unlock()
+
+		select {
+		case <-p.quit:
+			return
+		case <-time.After(p.config.ReconnectDelay):
+		}
+
+		ok, err := p.reconnect(pc)
+		if err != nil {
+			// Sent without opMu held, as forward blocks until Listen
+			// takes the message or Close runs.
+			p.forward(map[string]interface{}{
+				"error":   true,
+				"message": fmt.Sprintf("resubscribe after reconnect: %v", err),
+			})
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// reconnect reopens a dropped connection and rebalances its topics. It
+// reports false when the pool was closed, and the error of resubscribing;
+// topics that could not be placed are left to retryPending. Holding opMu
+// keeps Subscribe, Unsubscribe and Close out while the connection changes.
+func (p *StreamPool) reconnect(pc *poolConn) (bool, error) {
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.RLock()
+	closed := p.closed
+	p.mu.RUnlock()
+	if closed {
+		return false, nil
+	}
+
+	if err := pc.ws.Connect(); err != nil {
+		return true, nil
+	}
+
+	p.mu.Lock()
+	pc.down = false
+	topics := pc.topics
+	pc.topics = nil
+	for _, topic := range topics {
+		delete(p.topics, topic)
+	}
+	p.mu.Unlock()
+
+	pc.ws.resetSubscriptions()
+
+	// The reconnected connection is empty again, so the topics spread over
+	// the least loaded connections including it.
+	err := p.assign(topics)
+	if err != nil {
+		p.deferTopics(topics)
+	}
+	return true, err
+}
+
+// deferTopics queues the topics that assign did not place for
+// retryPending, starting it if needed. The caller must hold opMu.
+func (p *StreamPool) deferTopics(topics []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, topic := range topics {
+		if _, ok := p.topics[topic]; !ok && !containsString(p.pending, topic) {
+			p.pending = append(p.pending, topic)
+		}
+	}
+	if len(p.pending) > 0 && !p.retrying && !p.closed {
+		p.retrying = true
+		p.wg.Add(1)
+		go p.retryPending()
+	}
+}
+
+// retryPending subscribes the pending topics every ReconnectDelay until
+// they are all placed or the pool is closed.
+func (p *StreamPool) retryPending() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-p.quit:
+			return
+		case <-time.After(p.config.ReconnectDelay):
+		}
+
+		if done, err := p.assignPending(); done {
+			return
+		} else if err != nil {
+			p.forward(map[string]interface{}{
+				"error":   true,
+				"message": fmt.Sprintf("resubscribe pending topics: %v", err),
+			})
+		}
+	}
+}
+
+// assignPending makes one attempt at placing the pending topics. It
+// reports true once nothing is left to retry.
+func (p *StreamPool) assignPending() (bool, error) {
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return true, nil
+	}
+	var topics []string
+	for _, topic := range p.pending {
+		// Subscribe may have placed a topic in the meantime.
+		if _, ok := p.topics[topic]; !ok {
+			topics = append(topics, topic)
+		}
+	}
+	p.pending = nil
+	p.mu.Unlock()
+
+	err := p.assign(topics)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, topic := range topics {
+		if _, ok := p.topics[topic]; !ok {
+			p.pending = append(p.pending, topic)
+		}
+	}
+	if len(p.pending) == 0 {
+		p.retrying = false
+		return true, nil
+	}
+	return false, err
+}
+
+func chunkStrings(values []string, size int) [][]string {
+	var chunks [][]string
+	for len(values) > size {
+		chunks = append(chunks, values[:size])
+		values = values[size:]
+	}
+	if len(values) > 0 {
+		chunks = append(chunks, values)
+	}
+	return chunks
+}
+
+func removeString(values []string, value string) []string {
+	for i, v := range values {
+		if v == value {
+			return append(values[:i], values[i+1:]...)
+		}
+	}
+	return values
+}
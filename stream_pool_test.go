@@ -0,0 +1,189 @@
+package bybit
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// poolServer is a fake public stream acknowledging subscriptions. While
+// rejects is positive each subscribe request is rejected and counts it
+// down; drop closes every open connection.
+type poolServer struct {
+	rejects int32
+	mu      sync.Mutex
+	conns   []*websocket.Conn
+}
+
+func (s *poolServer) handle(conn *websocket.Conn, msg map[string]interface{}) {
+	s.mu.Lock()
+	if !containsConn(s.conns, conn) {
+		s.conns = append(s.conns, conn)
+	}
+	s.mu.Unlock()
+
+	switch msg["op"] {
+	case "subscribe", "unsubscribe":
+		success := msg["op"] == "unsubscribe" || atomic.AddInt32(&s.rejects, -1) < 0
+		conn.WriteJSON(map[string]interface{}{
+			"op":      msg["op"],
+			"req_id":  msg["req_id"],
+			"success": success,
+			"ret_msg": "",
+		})
+	case "flood":
+		for i := 0; i < int(msg["n"].(float64)); i++ {
+			conn.WriteJSON(map[string]interface{}{"topic": "tickers.BTCUSDT", "ts": 1})
+		}
+	}
+}
+
+func (s *poolServer) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func containsConn(conns []*websocket.Conn, conn *websocket.Conn) bool {
+	for _, c := range conns {
+		if c == conn {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestPool(t *testing.T, server *poolServer) *StreamPool {
+	srv := fakeStream(t, server.handle)
+	p := NewStreamPool(StreamPoolConfig{
+		WebSocketConfig: WebSocketConfig{AckTimeout: 2 * time.Second},
+		ReconnectDelay:  20 * time.Millisecond,
+	})
+	p.url = "ws" + strings.TrimPrefix(srv.URL, "http")
+	return p
+}
+
+func (p *StreamPool) pendingTopics() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]string(nil), p.pending...)
+}
+
+// waitFor polls cond until it holds or fails the test after a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStreamPoolRetriesPendingTopics(t *testing.T) {
+	server := &poolServer{}
+	p := newTestPool(t, server)
+	defer p.Close()
+
+	var mu sync.Mutex
+	var errs []string
+	p.OnMessage(func(data map[string]interface{}) {
+		if data["error"] == true {
+			mu.Lock()
+			errs = append(errs, data["message"].(string))
+			mu.Unlock()
+		}
+	})
+	go p.Listen()
+
+	topics := []string{"tickers.BTCUSDT", "tickers.ETHUSDT"}
+	if err := p.Subscribe(topics); err != nil {
+		t.Fatal(err)
+	}
+
+	// The resubscribe after the reconnect and the first retry fail.
+	atomic.StoreInt32(&server.rejects, 2)
+	server.drop()
+
+	waitFor(t, "two failed resubscribes", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		var n int
+		for _, msg := range errs {
+			if strings.HasPrefix(msg, "resubscribe") {
+				n++
+			}
+		}
+		return n == 2
+	})
+	waitFor(t, "the topics to be resubscribed", func() bool {
+		return reflect.DeepEqual(p.GetSubscriptions(), topics) && len(p.pendingTopics()) == 0
+	})
+}
+
+func TestStreamPoolUnsubscribePendingTopic(t *testing.T) {
+	server := &poolServer{}
+	p := newTestPool(t, server)
+	defer p.Close()
+	go p.Listen()
+
+	if err := p.Subscribe([]string{"tickers.BTCUSDT", "tickers.ETHUSDT"}); err != nil {
+		t.Fatal(err)
+	}
+
+	atomic.StoreInt32(&server.rejects, 1<<30)
+	server.drop()
+	waitFor(t, "pending topics", func() bool { return len(p.pendingTopics()) == 2 })
+
+	if err := p.Unsubscribe([]string{"tickers.BTCUSDT"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.pendingTopics(); !reflect.DeepEqual(got, []string{"tickers.ETHUSDT"}) {
+		t.Errorf("pending = %v, want [tickers.ETHUSDT]", got)
+	}
+
+	atomic.StoreInt32(&server.rejects, 0)
+	waitFor(t, "the remaining topic", func() bool {
+		return reflect.DeepEqual(p.GetSubscriptions(), []string{"tickers.ETHUSDT"})
+	})
+}
+
+func TestStreamPoolCloseWhileReconnectBlocks(t *testing.T) {
+	server := &poolServer{}
+	p := newTestPool(t, server)
+
+	if err := p.Subscribe([]string{"tickers.BTCUSDT"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing listens on the pool. Fill its event buffer to one slot short,
+	// which the drop error takes, so the reconnect error has nowhere to go.
+	waitFor(t, "the subscribe ack", func() bool { return len(p.events) == 1 })
+	if err := p.conns[0].ws.Send(map[string]interface{}{"op": "flood", "n": inboxSize - 2}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the flood", func() bool { return len(p.events) == inboxSize-1 })
+
+	atomic.StoreInt32(&server.rejects, 1<<30)
+	server.drop()
+	waitFor(t, "the failed resubscribe", func() bool { return len(p.pendingTopics()) == 1 })
+
+	closed := make(chan error, 1)
+	go func() { closed <- p.Close() }()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close blocked behind the reconnect")
+	}
+
+	p.wg.Wait()
+}
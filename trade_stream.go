@@ -0,0 +1,347 @@
+package bybit
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WebSocket Trade API operations.
+const (
+	TradeOpCreate      = "order.create"
+	TradeOpAmend       = "order.amend"
+	TradeOpCancel      = "order.cancel"
+	TradeOpCreateBatch = "order.create-batch"
+	TradeOpAmendBatch  = "order.amend-batch"
+	TradeOpCancelBatch = "order.cancel-batch"
+)
+
+// OrderSender is implemented by both Client (REST) and TradeStream
+// (WebSocket), so strategies can swap the order entry transport.
+type OrderSender interface {
+	CreateOrder(params map[string]interface{}) (map[string]interface{}, error)
+	AmendOrder(params map[string]interface{}) (map[string]interface{}, error)
+	CancelOrder(params map[string]interface{}) (map[string]interface{}, error)
+}
+
+// TradeStreamConfig configures a TradeStream.
+type TradeStreamConfig struct {
+	APIKey     string
+	APISecret  string
+	Testnet    bool
+	Region     string
+	RecvWindow int
+	// Timeout bounds the wait for a response to each request. Defaults to 10s.
+	Timeout time.Duration
+	// Referer is sent in the request header for broker attribution.
+	Referer string
+	// ReconnectDelay is the wait before reconnecting and re-authenticating
+	// a dropped connection. Defaults to 2s; a negative value disables
+	// reconnecting.
+	ReconnectDelay time.Duration
+}
+
+// TradeStream places, amends and cancels orders over the /v5/trade
+// WebSocket. Responses are correlated with requests by reqId and returned
+// in the same shape as the REST order endpoints.
+//
+// A dropped connection fails the in-flight requests and is reconnected in
+// the background; requests made while disconnected fail immediately with
+// an error wrapping ErrNotConnected.
+type TradeStream struct {
+	ws             *WebSocket
+	recvWindow     int
+	timeout        time.Duration
+	referer        string
+	reconnectDelay time.Duration
+	pending        map[string]chan map[string]interface{}
+	quit           chan struct{}
+	reqSeq         uint64
+	mu             sync.Mutex
+}
+
+// NewTradeStream creates a Trade API client. Call Connect before sending orders.
+func NewTradeStream(config TradeStreamConfig) *TradeStream {
+	if config.RecvWindow == 0 {
+		config.RecvWindow = 5000
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.ReconnectDelay == 0 {
+		config.ReconnectDelay = 2 * time.Second
+	}
+
+	ws := NewWebSocket(WebSocketConfig{
+		APIKey:    config.APIKey,
+		APISecret: config.APISecret,
+		Testnet:   config.Testnet,
+		Region:    config.Region,
+		IsPrivate: true,
+	})
+	ws.path = "/v5/trade"
+
+	ts := &TradeStream{
+		ws:             ws,
+		recvWindow:     config.RecvWindow,
+		timeout:        config.Timeout,
+		referer:        config.Referer,
+		reconnectDelay: config.ReconnectDelay,
+		pending:        make(map[string]chan map[string]interface{}),
+	}
+	ws.OnMessage(ts.handleMessage)
+
+	return ts
+}
+
+// Connect opens and authenticates the Trade API connection and starts
+// reading responses in the background.
+func (ts *TradeStream) Connect() error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.quit != nil {
+		return fmt.Errorf("trade stream already connected")
+	}
+	if err := ts.ws.Connect(); err != nil {
+		return err
+	}
+
+	ts.quit = make(chan struct{})
+	go ts.run(ts.quit)
+
+	return nil
+}
+
+// run reads responses until the connection drops, then fails the
+// in-flight requests and reconnects, which authenticates again, until
+// Close is called.
+func (ts *TradeStream) run(quit chan struct{}) {
+	for {
+		ts.ws.Listen()
+		ts.failPending(fmt.Errorf("trade stream connection closed"))
+
+		if ts.reconnectDelay < 0 {
+			ts.mu.Lock()
+			if ts.quit == quit {
+				ts.quit = nil
+			}
+			ts.mu.Unlock()
+			return
+		}
+		for {
+			select {
+			case <-quit:
+				return
+			case <-time.After(ts.reconnectDelay):
+			}
+
+			if err := ts.ws.Connect(); err != nil {
+				continue
+			}
+			select {
+			case <-quit:
+				// Closed while reconnecting.
+				ts.ws.Close()
+				return
+			default:
+			}
+			break
+		}
+	}
+}
+
+// Close closes the connection, stops reconnecting and fails all in-flight
+// requests.
+func (ts *TradeStream) Close() error {
+	ts.mu.Lock()
+	if ts.quit != nil {
+		close(ts.quit)
+		ts.quit = nil
+	}
+	ts.mu.Unlock()
+
+	err := ts.ws.Close()
+	ts.failPending(fmt.Errorf("trade stream closed"))
+	return err
+}
+
+// IsConnected reports whether the underlying connection is open.
+func (ts *TradeStream) IsConnected() bool {
+	return ts.ws.IsConnected()
+}
+
+// Ping sends an application level ping on the Trade API connection.
+func (ts *TradeStream) Ping() error {
+	return ts.ws.Ping()
+}
+
+// CreateOrder places an order. params are the same as Client.CreateOrder.
+func (ts *TradeStream) CreateOrder(params map[string]interface{}) (map[string]interface{}, error) {
+	return ts.Request(TradeOpCreate, []interface{}{params})
+}
+
+// AmendOrder amends an open order. params are the same as Client.AmendOrder.
+func (ts *TradeStream) AmendOrder(params map[string]interface{}) (map[string]interface{}, error) {
+	return ts.Request(TradeOpAmend, []interface{}{params})
+}
+
+// CancelOrder cancels an open order. params are the same as Client.CancelOrder.
+func (ts *TradeStream) CancelOrder(params map[string]interface{}) (map[string]interface{}, error) {
+	return ts.Request(TradeOpCancel, []interface{}{params})
+}
+
+// BatchPlaceOrder places several orders of one category in a single request.
+func (ts *TradeStream) BatchPlaceOrder(category string, orders []map[string]interface{}) (map[string]interface{}, error) {
+	return ts.Request(TradeOpCreateBatch, []interface{}{batchArgs(category, orders)})
+}
+
+// BatchAmendOrder amends several orders of one category in a single request.
+func (ts *TradeStream) BatchAmendOrder(category string, orders []map[string]interface{}) (map[string]interface{}, error) {
+	return ts.Request(TradeOpAmendBatch, []interface{}{batchArgs(category, orders)})
+}
+
+// BatchCancelOrder cancels several orders of one category in a single request.
+func (ts *TradeStream) BatchCancelOrder(category string, orders []map[string]interface{}) (map[string]interface{}, error) {
+	return ts.Request(TradeOpCancelBatch, []interface{}{batchArgs(category, orders)})
+}
+
+// PlaceTradFiOrder places a TradFi order over the Trade API.
+func (ts *TradeStream) PlaceTradFiOrder(p TradFiOrderParams) (map[string]interface{}, error) {
+	return ts.CreateOrder(tradFiOrderPayload(p))
+}
+
+// Request sends a raw Trade API operation and waits for its response.
+// The response is converted to the REST layout: retCode, retMsg, result,
+// retExtInfo and time. A non-zero retCode is returned as data, not as error,
+// matching Client.Request.
+func (ts *TradeStream) Request(op string, args []interface{}) (map[string]interface{}, error) {
+	reqID := ts.nextReqID()
+	respCh := make(chan map[string]interface{}, 1)
+
+	ts.mu.Lock()
+	ts.pending[reqID] = respCh
+	ts.mu.Unlock()
+
+	message := map[string]interface{}{
+		"reqId":  reqID,
+		"header": ts.header(),
+		"op":     op,
+		"args":   args,
+	}
+
+	if err := ts.ws.Send(message); err != nil {
+		ts.removePending(reqID)
+		return nil, fmt.Errorf("%s %s: %w", op, reqID, err)
+	}
+
+	timer := time.NewTimer(ts.timeout)
+	defer timer.Stop()
+
+	select {
+	case resp := <-respCh:
+		if errMsg, ok := resp["error"].(string); ok {
+			return nil, fmt.Errorf("%s %s: %s", op, reqID, errMsg)
+		}
+		return tradeResponseToREST(resp), nil
+	case <-timer.C:
+		ts.removePending(reqID)
+		return nil, fmt.Errorf("%s %s: timed out after %s", op, reqID, ts.timeout)
+	}
+}
+
+func (ts *TradeStream) header() map[string]string {
+	header := map[string]string{
+		"X-BAPI-TIMESTAMP":   strconv.FormatInt(time.Now().UnixMilli(), 10),
+		"X-BAPI-RECV-WINDOW": strconv.Itoa(ts.recvWindow),
+	}
+	if ts.referer != "" {
+		header["Referer"] = ts.referer
+	}
+	return header
+}
+
+func (ts *TradeStream) nextReqID() string {
+	n := atomic.AddUint64(&ts.reqSeq, 1)
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatUint(n, 10)
+}
+
+func (ts *TradeStream) handleMessage(data map[string]interface{}) {
+	if isErr, ok := data["error"].(bool); ok && isErr {
+		msg, _ := data["message"].(string)
+		ts.failPending(fmt.Errorf("%s", msg))
+		return
+	}
+
+	reqID, _ := data["reqId"].(string)
+	if reqID == "" {
+		return
+	}
+
+	ts.mu.Lock()
+	respCh, ok := ts.pending[reqID]
+	delete(ts.pending, reqID)
+	ts.mu.Unlock()
+
+	if ok {
+		respCh <- data
+	}
+}
+
+func (ts *TradeStream) removePending(reqID string) {
+	ts.mu.Lock()
+	delete(ts.pending, reqID)
+	ts.mu.Unlock()
+}
+
+func (ts *TradeStream) failPending(err error) {
+	ts.mu.Lock()
+	pending := ts.pending
+	ts.pending = make(map[string]chan map[string]interface{})
+	ts.mu.Unlock()
+
+	for _, respCh := range pending {
+		respCh <- map[string]interface{}{"error": err.Error()}
+	}
+}
+
+func batchArgs(category string, orders []map[string]interface{}) map[string]interface{} {
+	request := make([]interface{}, len(orders))
+	for i, order := range orders {
+		request[i] = order
+	}
+	return map[string]interface{}{
+		"category": category,
+		"request":  request,
+	}
+}
+
+// tradeResponseToREST maps a Trade API response onto the REST response layout.
+func tradeResponseToREST(resp map[string]interface{}) map[string]interface{} {
+	result := resp["data"]
+	if result == nil {
+		result = map[string]interface{}{}
+	}
+
+	out := map[string]interface{}{
+		"retCode":    resp["retCode"],
+		"retMsg":     resp["retMsg"],
+		"result":     result,
+		"retExtInfo": resp["retExtInfo"],
+	}
+	if header, ok := resp["header"].(map[string]interface{}); ok {
+		if now, ok := header["Timenow"].(string); ok {
+			if t, err := strconv.ParseInt(now, 10, 64); err == nil {
+				out["time"] = float64(t)
+			}
+		}
+	}
+	return out
+}
+
+var (
+	_ OrderSender = (*Client)(nil)
+	_ OrderSender = (*TradeStream)(nil)
+)
@@ -0,0 +1,114 @@
+package bybit
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeTradeStream returns a TradeStream connected to a fake /v5/trade
+// endpoint served by handle.
+func fakeTradeStream(t *testing.T, config TradeStreamConfig, handle func(conn *websocket.Conn, msg map[string]interface{})) *TradeStream {
+	t.Helper()
+
+	srv := fakeStream(t, handle)
+	ts := NewTradeStream(config)
+	ts.ws.url = "ws" + strings.TrimPrefix(srv.URL, "http")
+	if err := ts.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ts.Close() })
+	return ts
+}
+
+func TestTradeStreamMatchesResponsesByReqID(t *testing.T) {
+	const n = 10
+
+	// Answer in reverse order once every request arrived, echoing the
+	// orderLinkId so each caller can check it got its own response.
+	var pending []map[string]interface{}
+	ts := fakeTradeStream(t, TradeStreamConfig{Timeout: 5 * time.Second}, func(conn *websocket.Conn, msg map[string]interface{}) {
+		pending = append(pending, msg)
+		if len(pending) < n {
+			return
+		}
+		for i := len(pending) - 1; i >= 0; i-- {
+			req := pending[i]
+			order := req["args"].([]interface{})[0].(map[string]interface{})
+			conn.WriteJSON(map[string]interface{}{
+				"reqId":   req["reqId"],
+				"retCode": 0,
+				"retMsg":  "OK",
+				"op":      req["op"],
+				"data":    map[string]interface{}{"orderLinkId": order["orderLinkId"]},
+				"header":  map[string]interface{}{"Timenow": "1700000000000"},
+			})
+		}
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(linkID string) {
+			defer wg.Done()
+			res, err := ts.CreateOrder(map[string]interface{}{"orderLinkId": linkID})
+			if err != nil {
+				t.Errorf("%s: %v", linkID, err)
+				return
+			}
+			result, _ := res["result"].(map[string]interface{})
+			if result["orderLinkId"] != linkID {
+				t.Errorf("%s got the response of %v", linkID, result["orderLinkId"])
+			}
+			if res["time"] != float64(1700000000000) {
+				t.Errorf("time = %v", res["time"])
+			}
+		}(string(rune('a' + i)))
+	}
+	wg.Wait()
+}
+
+func TestTradeStreamFailsPendingOnDisconnect(t *testing.T) {
+	ts := fakeTradeStream(t, TradeStreamConfig{Timeout: 5 * time.Second, ReconnectDelay: -1}, func(conn *websocket.Conn, msg map[string]interface{}) {
+		conn.Close()
+	})
+
+	start := time.Now()
+	_, err := ts.CreateOrder(map[string]interface{}{"orderLinkId": "a"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("request waited %s for the timeout", elapsed)
+	}
+
+	waitFor(t, "the disconnect", func() bool { return !ts.IsConnected() })
+	if _, err := ts.CancelOrder(map[string]interface{}{"orderId": "1"}); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("request while disconnected = %v, want ErrNotConnected", err)
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if len(ts.pending) != 0 {
+		t.Errorf("%d requests left pending", len(ts.pending))
+	}
+}
+
+func TestTradeStreamIgnoresUnknownReqID(t *testing.T) {
+	ts := fakeTradeStream(t, TradeStreamConfig{Timeout: 5 * time.Second}, func(conn *websocket.Conn, msg map[string]interface{}) {
+		conn.WriteJSON(map[string]interface{}{"reqId": "unknown", "retCode": 10001, "retMsg": "stray"})
+		conn.WriteJSON(map[string]interface{}{"reqId": msg["reqId"], "retCode": 0, "retMsg": "OK"})
+	})
+
+	res, err := ts.CancelOrder(map[string]interface{}{"orderId": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res["retMsg"] != "OK" {
+		t.Errorf("response = %v, want the one matching the request", res)
+	}
+}
@@ -78,7 +78,7 @@ func (c *Client) GetTradFiInstruments(assetClass string) (map[string]interface{}
 	params := map[string]interface{}{
 		"category": TradFiCategoryLinear,
 	}
-	result, err := c.Request("GET", "/v5/market/instruments-info", params)
+	result, err := c.GetInstrumentsInfo(params)
 	if err != nil {
 		return nil, err
 	}
@@ -199,6 +199,11 @@ type TradFiOrderParams struct {
 
 // PlaceTradFiOrder places an order for a TradFi instrument (forex, metals, stocks, indices).
 func (c *Client) PlaceTradFiOrder(p TradFiOrderParams) (map[string]interface{}, error) {
+	return c.Request("POST", "/v5/order/create", tradFiOrderPayload(p))
+}
+
+// tradFiOrderPayload builds the order/create payload for a TradFi order.
+func tradFiOrderPayload(p TradFiOrderParams) map[string]interface{} {
 	if p.TimeInForce == "" {
 		p.TimeInForce = "GTC"
 	}
@@ -229,7 +234,7 @@ func (c *Client) PlaceTradFiOrder(p TradFiOrderParams) (map[string]interface{},
 		payload["orderLinkId"] = p.OrderLinkID
 	}
 
-	return c.Request("POST", "/v5/order/create", payload)
+	return payload
 }
 
 // CloseTradFiPosition closes an open TradFi position at market price.
@@ -0,0 +1,344 @@
+package bybit
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Account types used by the asset endpoints.
+const (
+	AccountTypeUnified    = "UNIFIED"
+	AccountTypeContract   = "CONTRACT"
+	AccountTypeSpot       = "SPOT"
+	AccountTypeFund       = "FUND"
+	AccountTypeOption     = "OPTION"
+	AccountTypeInvestment = "INVESTMENT"
+)
+
+// Transfer statuses.
+const (
+	TransferStatusSuccess = "SUCCESS"
+	TransferStatusPending = "PENDING"
+	TransferStatusFailed  = "FAILED"
+)
+
+// TransferRequest describes a transfer between account types. Setting
+// FromMemberID and ToMemberID makes it a universal transfer between master
+// and sub UIDs; otherwise the funds move within the current UID. Setting
+// only one of them is an error.
+type TransferRequest struct {
+	Coin            string
+	Amount          string
+	FromAccountType string
+	ToAccountType   string
+	FromMemberID    int64
+	ToMemberID      int64
+	// TransferID is generated when empty. Reusing the id of a failed call
+	// is safe: Bybit rejects duplicates, so a transfer never runs twice.
+	TransferID string
+	// Timeout bounds how long Transfer waits for a PENDING transfer to
+	// settle. Defaults to 30s.
+	Timeout time.Duration
+}
+
+func (r TransferRequest) universal() bool {
+	return r.FromMemberID != 0 || r.ToMemberID != 0
+}
+
+// TransferRecord is an item of the transfer history endpoints. The member
+// ids are only set for universal transfers.
+type TransferRecord struct {
+	TransferID      string `json:"transferId"`
+	Coin            string `json:"coin"`
+	Amount          string `json:"amount"`
+	FromMemberID    string `json:"fromMemberId"`
+	ToMemberID      string `json:"toMemberId"`
+	FromAccountType string `json:"fromAccountType"`
+	ToAccountType   string `json:"toAccountType"`
+	Timestamp       string `json:"timestamp"`
+	Status          string `json:"status"`
+}
+
+// TransferResult is the immediate result of a transfer call.
+type TransferResult struct {
+	TransferID string `json:"transferId"`
+	Status     string `json:"status"`
+}
+
+// TransferHistoryQuery filters transfer history. All fields are optional;
+// without Start and End Bybit returns the last 7 days.
+type TransferHistoryQuery struct {
+	TransferID string
+	Coin       string
+	Status     string
+	Start      time.Time
+	End        time.Time
+	Limit      int
+	Cursor     string
+}
+
+func (q TransferHistoryQuery) params() map[string]interface{} {
+	params := map[string]interface{}{}
+	if q.TransferID != "" {
+		params["transferId"] = q.TransferID
+	}
+	if q.Coin != "" {
+		params["coin"] = q.Coin
+	}
+	if q.Status != "" {
+		params["status"] = q.Status
+	}
+	if !q.Start.IsZero() {
+		params["startTime"] = q.Start.UnixMilli()
+	}
+	if !q.End.IsZero() {
+		params["endTime"] = q.End.UnixMilli()
+	}
+	if q.Limit > 0 {
+		params["limit"] = q.Limit
+	}
+	if q.Cursor != "" {
+		params["cursor"] = q.Cursor
+	}
+	return params
+}
+
+// NewTransferID returns a random UUID v4, the transferId format Bybit
+// requires.
+func NewTransferID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate transfer id: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// prepare validates a request and generates a missing TransferID.
+func (r *TransferRequest) prepare() error {
+	if (r.FromMemberID == 0) != (r.ToMemberID == 0) {
+		return fmt.Errorf("universal transfer needs both FromMemberID and ToMemberID")
+	}
+	if r.TransferID == "" {
+		id, err := NewTransferID()
+		if err != nil {
+			return err
+		}
+		r.TransferID = id
+	}
+	return nil
+}
+
+// SubmitTransfer sends an internal or universal transfer without waiting
+// for it to settle. A missing TransferID is generated.
+func (c *Client) SubmitTransfer(req TransferRequest) (*TransferResult, error) {
+	if err := req.prepare(); err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{
+		"transferId":      req.TransferID,
+		"coin":            req.Coin,
+		"amount":          req.Amount,
+		"fromAccountType": req.FromAccountType,
+		"toAccountType":   req.ToAccountType,
+	}
+
+	var res map[string]interface{}
+	var err error
+	if req.universal() {
+		params["fromMemberId"] = req.FromMemberID
+		params["toMemberId"] = req.ToMemberID
+		res, err = c.CreateUniversalTransfer(params)
+	} else {
+		res, err = c.CreateInternalTransfer(params)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := TransferResult{TransferID: req.TransferID}
+	if err := decodeResult(res, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Transfer sends a transfer and waits until Bybit reports it as SUCCESS.
+// It returns an error when the transfer fails or is still pending after
+// the timeout; the record then carries the last known status, and the
+// transfer id can be used to check it later. When the submission itself
+// errors, the transfer is looked up by id before reporting a failure, as
+// the request may have reached Bybit with only the response lost.
+func (c *Client) Transfer(req TransferRequest) (*TransferRecord, error) {
+	if err := req.prepare(); err != nil {
+		return nil, err
+	}
+	if req.Timeout == 0 {
+		req.Timeout = 30 * time.Second
+	}
+
+	var record *TransferRecord
+	result, err := c.SubmitTransfer(req)
+	if err != nil {
+		found, lookupErr := c.transferRecord(req.TransferID, req.universal())
+		if lookupErr != nil || found == nil {
+			return nil, fmt.Errorf("transfer %s: %w", req.TransferID, err)
+		}
+		record = found
+	} else {
+		record = &TransferRecord{
+			TransferID:      req.TransferID,
+			Coin:            req.Coin,
+			Amount:          req.Amount,
+			FromAccountType: req.FromAccountType,
+			ToAccountType:   req.ToAccountType,
+			Status:          result.Status,
+		}
+		if req.universal() {
+			record.FromMemberID = strconv.FormatInt(req.FromMemberID, 10)
+			record.ToMemberID = strconv.FormatInt(req.ToMemberID, 10)
+		}
+	}
+
+	deadline := time.Now().Add(req.Timeout)
+	delay := 500 * time.Millisecond
+	for {
+		switch record.Status {
+		case TransferStatusSuccess:
+			return record, nil
+		case TransferStatusFailed:
+			return record, fmt.Errorf("transfer %s failed", req.TransferID)
+		}
+		if time.Now().After(deadline) {
+			return record, fmt.Errorf("transfer %s still %s after %s", req.TransferID, record.Status, req.Timeout)
+		}
+
+		time.Sleep(delay)
+		if delay < 5*time.Second {
+			delay *= 2
+		}
+
+		found, err := c.transferRecord(req.TransferID, req.universal())
+		if err != nil {
+			return record, fmt.Errorf("transfer %s: %w", req.TransferID, err)
+		}
+		if found != nil {
+			record = found
+		}
+	}
+}
+
+func (c *Client) transferRecord(transferID string, universal bool) (*TransferRecord, error) {
+	q := TransferHistoryQuery{TransferID: transferID}
+
+	var list []TransferRecord
+	var err error
+	if universal {
+		list, _, err = c.UniversalTransfers(q)
+	} else {
+		list, _, err = c.InternalTransfers(q)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].TransferID == transferID {
+			return &list[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// InternalTransfers returns a page of transfers within the current UID and
+// the cursor of the next page.
+func (c *Client) InternalTransfers(q TransferHistoryQuery) ([]TransferRecord, string, error) {
+	res, err := c.GetInternalTransferRecords(q.params())
+	if err != nil {
+		return nil, "", err
+	}
+
+	var list []TransferRecord
+	cursor, err := decodeList(res, &list)
+	return list, cursor, err
+}
+
+// UniversalTransfers returns a page of transfers between master and sub
+// UIDs and the cursor of the next page.
+func (c *Client) UniversalTransfers(q TransferHistoryQuery) ([]TransferRecord, string, error) {
+	res, err := c.GetUniversalTransferRecords(q.params())
+	if err != nil {
+		return nil, "", err
+	}
+
+	var list []TransferRecord
+	cursor, err := decodeList(res, &list)
+	return list, cursor, err
+}
+
+// CoinBalance is the balance of a coin in an account type.
+type CoinBalance struct {
+	Coin            string `json:"coin"`
+	WalletBalance   string `json:"walletBalance"`
+	TransferBalance string `json:"transferBalance"`
+	Bonus           string `json:"bonus"`
+	// TransferSafeAmount and LtvTransferSafeAmount are only returned for a
+	// single coin query.
+	TransferSafeAmount    string `json:"transferSafeAmount"`
+	LtvTransferSafeAmount string `json:"ltvTransferSafeAmount"`
+}
+
+// CoinBalances returns the balances of an account type. coins optionally
+// restricts the result. memberID queries a sub UID from the master
+// account and may be 0.
+func (c *Client) CoinBalances(accountType string, memberID int64, coins ...string) ([]CoinBalance, error) {
+	params := map[string]interface{}{"accountType": accountType}
+	if memberID != 0 {
+		params["memberId"] = memberID
+	}
+	if len(coins) > 0 {
+		params["coin"] = strings.Join(coins, ",")
+	}
+
+	res, err := c.GetAllCoinsBalance(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Balance []CoinBalance `json:"balance"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return nil, err
+	}
+	return result.Balance, nil
+}
+
+// CoinBalanceOf returns the balance of one coin in an account type,
+// including the amount that can be transferred out safely.
+func (c *Client) CoinBalanceOf(accountType, coin string, memberID int64) (*CoinBalance, error) {
+	params := map[string]interface{}{
+		"accountType": accountType,
+		"coin":        coin,
+	}
+	if memberID != 0 {
+		params["memberId"] = memberID
+	}
+
+	res, err := c.GetSingleCoinBalance(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Balance CoinBalance `json:"balance"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return nil, err
+	}
+	return &result.Balance, nil
+}
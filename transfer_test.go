@@ -0,0 +1,89 @@
+package bybit
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTransferChecksStatusAfterSubmitError(t *testing.T) {
+	const id = "8f8b2b4e-5b4a-4c2e-9d7a-3c1f0e6a9b21"
+
+	tests := []struct {
+		name      string
+		universal bool
+		history   string
+		wantErr   bool
+	}{
+		{
+			name:    "internal transfer went through",
+			history: `{"retCode":0,"result":{"list":[{"transferId":"` + id + `","status":"SUCCESS"}]}}`,
+		},
+		{
+			name:      "universal transfer went through",
+			universal: true,
+			history:   `{"retCode":0,"result":{"list":[{"transferId":"` + id + `","status":"SUCCESS"}]}}`,
+		},
+		{
+			name:    "transfer never arrived",
+			history: `{"retCode":0,"result":{"list":[]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "status lookup fails too",
+			history: `{"retCode":10016,"retMsg":"server error"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			client, err := NewClient(ClientConfig{
+				APIKey:    "key",
+				APISecret: "secret",
+				HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					paths = append(paths, req.URL.Path)
+					if req.Method == http.MethodPost {
+						return nil, fmt.Errorf("connection reset")
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     make(http.Header),
+						Body:       io.NopCloser(strings.NewReader(tt.history)),
+						Request:    req,
+					}, nil
+				})},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := TransferRequest{
+				Coin:            "USDT",
+				Amount:          "10",
+				FromAccountType: AccountTypeFund,
+				ToAccountType:   AccountTypeUnified,
+				TransferID:      id,
+			}
+			wantLookup := "/v5/asset/transfer/query-inter-transfer-list"
+			if tt.universal {
+				req.FromMemberID, req.ToMemberID = 1, 2
+				wantLookup = "/v5/asset/transfer/query-universal-transfer-list"
+			}
+
+			record, err := client.Transfer(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (record.TransferID != id || record.Status != TransferStatusSuccess) {
+				t.Errorf("record = %+v", record)
+			}
+			if len(paths) != 2 || paths[1] != wantLookup {
+				t.Errorf("requests = %v, want the submit and %s", paths, wantLookup)
+			}
+		})
+	}
+}
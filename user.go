@@ -0,0 +1,431 @@
+package bybit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sub-account member types.
+const (
+	MemberTypeNormal    = 1
+	MemberTypeCustodial = 6
+)
+
+// Sub-account statuses, as in SubMember.Status.
+const (
+	SubMemberStatusNormal      = 1
+	SubMemberStatusLoginBanned = 2
+	SubMemberStatusFrozen      = 4
+)
+
+// API key permission values, grouped by the APIPermissions field they
+// belong to.
+const (
+	PermissionOrder                 = "Order"
+	PermissionPosition              = "Position"
+	PermissionSpotTrade             = "SpotTrade"
+	PermissionAccountTransfer       = "AccountTransfer"
+	PermissionSubMemberTransfer     = "SubMemberTransfer"
+	PermissionSubMemberTransferList = "SubMemberTransferList"
+	PermissionWithdraw              = "Withdraw"
+	PermissionOptionsTrade          = "OptionsTrade"
+	PermissionDerivativesTrade      = "DerivativesTrade"
+	PermissionExchangeHistory       = "ExchangeHistory"
+	PermissionCopyTrading           = "CopyTrading"
+	PermissionBlockTrade            = "BlockTrade"
+	PermissionAffiliate             = "Affiliate"
+	PermissionEarn                  = "Earn"
+	PermissionNFTQueryProductList   = "NFTQueryProductList"
+)
+
+// APIPermissions is the permission set of an API key. Each field lists the
+// granted permissions of a product, e.g. ContractTrade holding
+// PermissionOrder and PermissionPosition.
+type APIPermissions struct {
+	ContractTrade []string `json:"ContractTrade,omitempty"`
+	Spot          []string `json:"Spot,omitempty"`
+	Wallet        []string `json:"Wallet,omitempty"`
+	Options       []string `json:"Options,omitempty"`
+	Derivatives   []string `json:"Derivatives,omitempty"`
+	Exchange      []string `json:"Exchange,omitempty"`
+	Earn          []string `json:"Earn,omitempty"`
+	CopyTrading   []string `json:"CopyTrading,omitempty"`
+	BlockTrade    []string `json:"BlockTrade,omitempty"`
+	Affiliate     []string `json:"Affiliate,omitempty"`
+	NFT           []string `json:"NFT,omitempty"`
+}
+
+func (p APIPermissions) params() map[string]interface{} {
+	params := map[string]interface{}{}
+	for key, values := range map[string][]string{
+		"ContractTrade": p.ContractTrade,
+		"Spot":          p.Spot,
+		"Wallet":        p.Wallet,
+		"Options":       p.Options,
+		"Derivatives":   p.Derivatives,
+		"Exchange":      p.Exchange,
+		"Earn":          p.Earn,
+		"CopyTrading":   p.CopyTrading,
+		"BlockTrade":    p.BlockTrade,
+		"Affiliate":     p.Affiliate,
+		"NFT":           p.NFT,
+	} {
+		if len(values) > 0 {
+			params[key] = values
+		}
+	}
+	return params
+}
+
+// SubMember is a sub UID of the master account.
+type SubMember struct {
+	UID         string `json:"uid"`
+	Username    string `json:"username"`
+	MemberType  int    `json:"memberType"`
+	Status      int    `json:"status"`
+	AccountMode int    `json:"accountMode"`
+	Remark      string `json:"remark"`
+}
+
+// SubMemberRequest describes a new sub UID.
+type SubMemberRequest struct {
+	Username string
+	// Password is only used by custodial sub-accounts.
+	Password   string
+	MemberType int
+	// QuickLogin enables login from the master account UI.
+	QuickLogin bool
+	Note       string
+}
+
+// CreateSubUID creates a sub UID under the master account.
+func (c *Client) CreateSubUID(req SubMemberRequest) (*SubMember, error) {
+	if req.MemberType == 0 {
+		req.MemberType = MemberTypeNormal
+	}
+
+	params := map[string]interface{}{
+		"username":   req.Username,
+		"memberType": req.MemberType,
+	}
+	if req.Password != "" {
+		params["password"] = req.Password
+	}
+	if req.QuickLogin {
+		params["switch"] = 1
+	}
+	if req.Note != "" {
+		params["note"] = req.Note
+	}
+
+	res, err := c.CreateSubMember(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var member SubMember
+	if err := decodeResult(res, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// SubUIDs returns a page of sub UIDs and the cursor of the next page;
+// pageSize is at most 100.
+func (c *Client) SubUIDs(pageSize int, cursor string) ([]SubMember, string, error) {
+	params := map[string]interface{}{}
+	if pageSize > 0 {
+		params["pageSize"] = pageSize
+	}
+	if cursor != "" {
+		params["nextCursor"] = cursor
+	}
+
+	res, err := c.GetSubMembersPaged(params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var result struct {
+		SubMembers []SubMember `json:"subMembers"`
+		NextCursor string      `json:"nextCursor"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return nil, "", err
+	}
+	// The last page reports cursor "0".
+	if result.NextCursor == "0" {
+		result.NextCursor = ""
+	}
+	return result.SubMembers, result.NextCursor, nil
+}
+
+// AllSubUIDs returns every sub UID of the master account.
+func (c *Client) AllSubUIDs() ([]SubMember, error) {
+	var members []SubMember
+	cursor := ""
+	for {
+		page, next, err := c.SubUIDs(100, cursor)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, page...)
+
+		if next == "" || len(page) == 0 {
+			return members, nil
+		}
+		cursor = next
+	}
+}
+
+// SetSubUIDFrozen freezes or unfreezes a sub UID.
+func (c *Client) SetSubUIDFrozen(subUID int64, frozen bool) error {
+	value := 0
+	if frozen {
+		value = 1
+	}
+
+	res, err := c.FreezeSubMember(map[string]interface{}{
+		"subuid": subUID,
+		"frozen": value,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = checkResponse(res)
+	return err
+}
+
+// APIKeyRequest describes the settings of a new API key. Without IPs the
+// key is not bound to any address and expires after 90 days of inactivity.
+type APIKeyRequest struct {
+	Note        string
+	ReadOnly    bool
+	IPs         []string
+	Permissions APIPermissions
+}
+
+func (r APIKeyRequest) params() map[string]interface{} {
+	readOnly := 0
+	if r.ReadOnly {
+		readOnly = 1
+	}
+
+	params := map[string]interface{}{
+		"readOnly":    readOnly,
+		"permissions": r.Permissions.params(),
+	}
+	if r.Note != "" {
+		params["note"] = r.Note
+	}
+	if len(r.IPs) > 0 {
+		params["ips"] = strings.Join(r.IPs, ",")
+	}
+	return params
+}
+
+// APIKeyUpdate describes changes to an existing API key. Apart from IPs,
+// only the fields that are set are sent and everything else keeps its
+// current value.
+type APIKeyUpdate struct {
+	Note     string
+	ReadOnly *bool
+	// IPs replaces the bound addresses and is required, as Bybit unbinds
+	// a key updated without ips. Pass the current addresses (APIKey.IPs)
+	// to keep them, or "*" to unbind the key.
+	IPs []string
+	// Permissions replaces the whole permission set.
+	Permissions *APIPermissions
+}
+
+func (u APIKeyUpdate) params() (map[string]interface{}, error) {
+	if len(u.IPs) == 0 {
+		return nil, fmt.Errorf("api key update needs IPs; pass \"*\" to unbind the key")
+	}
+
+	params := map[string]interface{}{"ips": strings.Join(u.IPs, ",")}
+	if u.Note != "" {
+		params["note"] = u.Note
+	}
+	if u.ReadOnly != nil {
+		readOnly := 0
+		if *u.ReadOnly {
+			readOnly = 1
+		}
+		params["readOnly"] = readOnly
+	}
+	if u.Permissions != nil {
+		params["permissions"] = u.Permissions.params()
+	}
+	return params, nil
+}
+
+// APIKey describes an API key. Secret is only returned when the key is
+// created.
+type APIKey struct {
+	ID          string         `json:"id"`
+	Note        string         `json:"note"`
+	APIKey      string         `json:"apiKey"`
+	ReadOnly    int            `json:"readOnly"`
+	Secret      string         `json:"secret"`
+	Permissions APIPermissions `json:"permissions"`
+	IPs         []string       `json:"ips"`
+	// Type is 1 for a personal key, 2 for a third-party app key.
+	Type        int    `json:"type"`
+	Status      int    `json:"status"`
+	DeadlineDay int    `json:"deadlineDay"`
+	ExpiredAt   string `json:"expiredAt"`
+	CreatedAt   string `json:"createdAt"`
+	UserID      int64  `json:"userID"`
+	IsMaster    bool   `json:"isMaster"`
+	ParentUID   string `json:"parentUid"`
+	Unified     int    `json:"unified"`
+	UTA         int    `json:"uta"`
+	VIPLevel    string `json:"vipLevel"`
+}
+
+// ExpiresAt returns when the key expires, zero for keys bound to IPs,
+// which do not expire.
+func (k *APIKey) ExpiresAt() time.Time {
+	if k.ExpiredAt == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, k.ExpiredAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// CreateSubAccountAPIKey creates an API key for a sub UID from the master
+// account.
+func (c *Client) CreateSubAccountAPIKey(subUID int64, req APIKeyRequest) (*APIKey, error) {
+	params := req.params()
+	params["subuid"] = subUID
+
+	return apiKeyResult(c.CreateSubAPIKey(params))
+}
+
+// ModifyMasterAPIKey changes the settings of the master API key used by the
+// client.
+func (c *Client) ModifyMasterAPIKey(update APIKeyUpdate) (*APIKey, error) {
+	params, err := update.params()
+	if err != nil {
+		return nil, err
+	}
+	return apiKeyResult(c.UpdateMasterAPIKey(params))
+}
+
+// ModifySubAccountAPIKey changes the settings of a sub-account API key.
+// apiKey selects the key when called with the master API key; leave it
+// empty when the client uses the sub-account key itself.
+func (c *Client) ModifySubAccountAPIKey(apiKey string, update APIKeyUpdate) (*APIKey, error) {
+	params, err := update.params()
+	if err != nil {
+		return nil, err
+	}
+	if apiKey != "" {
+		params["apikey"] = apiKey
+	}
+	return apiKeyResult(c.UpdateSubAPIKey(params))
+}
+
+// RemoveMasterAPIKey deletes the master API key used by the client. The
+// client cannot make further requests afterwards.
+func (c *Client) RemoveMasterAPIKey() error {
+	res, err := c.DeleteMasterAPIKey()
+	if err != nil {
+		return err
+	}
+	_, err = checkResponse(res)
+	return err
+}
+
+// RemoveSubAccountAPIKey deletes a sub-account API key. apiKey selects the
+// key when called with the master API key; leave it empty to delete the
+// sub-account key used by the client.
+func (c *Client) RemoveSubAccountAPIKey(apiKey string) error {
+	params := map[string]interface{}{}
+	if apiKey != "" {
+		params["apikey"] = apiKey
+	}
+
+	res, err := c.DeleteSubAPIKey(params)
+	if err != nil {
+		return err
+	}
+	_, err = checkResponse(res)
+	return err
+}
+
+// APIKeyDetails returns the settings and expiry of the API key used by the
+// client.
+func (c *Client) APIKeyDetails() (*APIKey, error) {
+	return apiKeyResult(c.GetAPIKeyInfo())
+}
+
+// SubAccountAPIKeys returns a page of the API keys of a sub UID and the
+// cursor of the next page; limit is at most 20.
+func (c *Client) SubAccountAPIKeys(subMemberID string, limit int, cursor string) ([]APIKey, string, error) {
+	params := map[string]interface{}{"subMemberId": subMemberID}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+	if cursor != "" {
+		params["cursor"] = cursor
+	}
+
+	res, err := c.GetSubAPIKeys(params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var result struct {
+		Result         []APIKey `json:"result"`
+		NextPageCursor string   `json:"nextPageCursor"`
+	}
+	if err := decodeResult(res, &result); err != nil {
+		return nil, "", err
+	}
+	return result.Result, result.NextPageCursor, nil
+}
+
+// AllSubAccountAPIKeys returns every API key of every sub UID, keyed by
+// sub UID.
+func (c *Client) AllSubAccountAPIKeys() (map[string][]APIKey, error) {
+	members, err := c.AllSubUIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string][]APIKey, len(members))
+	for _, member := range members {
+		cursor := ""
+		for {
+			page, next, err := c.SubAccountAPIKeys(member.UID, 20, cursor)
+			if err != nil {
+				return nil, err
+			}
+			keys[member.UID] = append(keys[member.UID], page...)
+
+			if next == "" || len(page) == 0 {
+				break
+			}
+			cursor = next
+		}
+	}
+	return keys, nil
+}
+
+func apiKeyResult(res map[string]interface{}, err error) (*APIKey, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	var key APIKey
+	if err := decodeResult(res, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
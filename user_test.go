@@ -0,0 +1,101 @@
+package bybit
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAPIKeyRequestParams(t *testing.T) {
+	params := APIKeyRequest{
+		Note:        "bot",
+		ReadOnly:    true,
+		IPs:         []string{"1.2.3.4", "5.6.7.8"},
+		Permissions: APIPermissions{Spot: []string{"SpotTrade"}},
+	}.params()
+
+	want := map[string]interface{}{
+		"note":        "bot",
+		"readOnly":    1,
+		"ips":         "1.2.3.4,5.6.7.8",
+		"permissions": map[string]interface{}{"Spot": []string{"SpotTrade"}},
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("params = %v, want %v", params, want)
+	}
+
+	if _, ok := (APIKeyRequest{}).params()["ips"]; ok {
+		t.Error("ips sent for a key without IPs")
+	}
+}
+
+func TestAPIKeyUpdateParams(t *testing.T) {
+	readOnly := false
+
+	tests := []struct {
+		name   string
+		update APIKeyUpdate
+		want   map[string]interface{}
+	}{
+		{
+			name:   "keep addresses",
+			update: APIKeyUpdate{IPs: []string{"1.2.3.4"}},
+			want:   map[string]interface{}{"ips": "1.2.3.4"},
+		},
+		{
+			name:   "unbind",
+			update: APIKeyUpdate{IPs: []string{"*"}, Note: "open"},
+			want:   map[string]interface{}{"ips": "*", "note": "open"},
+		},
+		{
+			name: "all fields",
+			update: APIKeyUpdate{
+				ReadOnly:    &readOnly,
+				IPs:         []string{"1.2.3.4", "5.6.7.8"},
+				Permissions: &APIPermissions{Wallet: []string{"AccountTransfer"}},
+			},
+			want: map[string]interface{}{
+				"readOnly":    0,
+				"ips":         "1.2.3.4,5.6.7.8",
+				"permissions": map[string]interface{}{"Wallet": []string{"AccountTransfer"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := tt.update.params()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(params, tt.want) {
+				t.Errorf("params = %v, want %v", params, tt.want)
+			}
+		})
+	}
+}
+
+func TestModifyAPIKeyRequiresIPs(t *testing.T) {
+	var requests int
+	client, err := NewClient(ClientConfig{
+		APIKey:    "key",
+		APISecret: "secret",
+		HTTPClient: fakeHTTPClient(func(req *http.Request) string {
+			requests++
+			return `{"retCode":0,"retMsg":"OK","result":{}}`
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ModifyMasterAPIKey(APIKeyUpdate{Note: "renamed"}); err == nil {
+		t.Error("expected an error for a master key update without IPs")
+	}
+	if _, err := client.ModifySubAccountAPIKey("sub", APIKeyUpdate{Note: "renamed"}); err == nil {
+		t.Error("expected an error for a sub key update without IPs")
+	}
+	if requests != 0 {
+		t.Errorf("requests = %d, want none", requests)
+	}
+}
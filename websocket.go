@@ -1,6 +1,7 @@
 package bybit
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/json"
@@ -8,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -19,11 +21,27 @@ type WebSocket struct {
 	testnet         bool
 	region          string
 	isPrivate       bool
+	demo            bool
+	category        string
+	path            string
+	url             string
+	dcpProducts     []string
+	metrics         *streamMetrics
 	conn            *websocket.Conn
+	inbox           chan wsMessage
+	outbox          chan wsWrite
+	done            chan struct{}
+	writeTimeout    time.Duration
 	subscriptions   []string
+	acks            map[string]*ackWaiter
+	ackTimeout      time.Duration
+	reqSeq          uint64
 	messageCallback func(map[string]interface{})
+	rawCallback     func([]byte)
+	onDrop          func(topic string)
 	mu              sync.RWMutex
 	connected       bool
+	dialing         bool
 }
 
 type WebSocketConfig struct {
@@ -32,12 +50,87 @@ type WebSocketConfig struct {
 	Testnet   bool
 	Region    string
 	IsPrivate bool
+	// Category selects the public endpoint: spot, linear, inverse or
+	// option. Defaults to spot. Ignored for private connections.
+	Category string
+	// Demo connects private streams to the demo trading environment
+	// (DemoWebSocketURL). Demo trading has no public streams, so public
+	// connections keep using mainnet market data. Region "demo" has the
+	// same effect.
+	Demo bool
+	// AckTimeout bounds the wait for subscribe, unsubscribe and auth
+	// responses. Defaults to 10s.
+	AckTimeout time.Duration
+	// WriteTimeout is the write deadline for each outbound frame.
+	// Defaults to 10s.
+	WriteTimeout time.Duration
+	// DCPProducts are subscribed to as dcp.{product} right after a private
+	// connection authenticates, which Bybit requires for Disconnection
+	// Protect to watch the connection. See Client.SetDCP.
+	DCPProducts []string
+	// MetricsHandler optionally receives latency, reconnect, ping and
+	// sequence gap events as they happen. Metrics are collected either way
+	// and available from WebSocket.Metrics.
+	MetricsHandler MetricsHandler
+	// OnDrop is called with the topic of every message dropped because
+	// Listen fell more than inboxSize messages behind, so the caller can
+	// resync the affected stream. The topic is empty for control frames.
+	// It runs on the read loop and must not block.
+	OnDrop func(topic string)
 }
 
+// ackWaiter is a pending subscribe, unsubscribe or auth request.
+type ackWaiter struct {
+	op string
+	ch chan map[string]interface{}
+}
+
+// wsMessage is a received frame queued for Listen: either decoded into
+// data, or kept as a pooled raw buffer when a raw callback is set.
+type wsMessage struct {
+	data map[string]interface{}
+	raw  *bytes.Buffer
+}
+
+// wsWrite is an outbound frame queued for the write pump.
+type wsWrite struct {
+	data   []byte
+	result chan error
+}
+
+const (
+	// inboxSize is the number of messages buffered between the read loop
+	// and Listen. Messages arriving while the buffer is full are dropped,
+	// counted in StreamMetrics.Dropped and reported to OnDrop, so a slow or
+	// missing Listen never stalls acknowledgements and pongs.
+	inboxSize = 4096
+	// outboxSize is the number of frames queued for the write pump.
+	outboxSize = 256
+)
+
+var errWebSocketClosed = fmt.Errorf("websocket connection closed")
+
+// ErrNotConnected is returned when sending on a WebSocket that is not
+// connected. Call Connect first, or again after the connection dropped.
+var ErrNotConnected = fmt.Errorf("websocket not connected")
+
+// ErrAlreadyConnected is returned by Connect while a connection is open or
+// being dialed. Close it first to open a new one.
+var ErrAlreadyConnected = fmt.Errorf("websocket already connected")
+
 func NewWebSocket(config WebSocketConfig) *WebSocket {
 	if config.Region == "" {
 		config.Region = "global"
 	}
+	if config.Category == "" {
+		config.Category = CategorySpot
+	}
+	if config.AckTimeout == 0 {
+		config.AckTimeout = 10 * time.Second
+	}
+	if config.WriteTimeout == 0 {
+		config.WriteTimeout = 10 * time.Second
+	}
 
 	return &WebSocket{
 		apiKey:        config.APIKey,
@@ -45,69 +138,108 @@ func NewWebSocket(config WebSocketConfig) *WebSocket {
 		testnet:       config.Testnet,
 		region:        config.Region,
 		isPrivate:     config.IsPrivate,
+		demo:          config.Demo || strings.ToLower(config.Region) == "demo",
+		category:      strings.ToLower(config.Category),
+		dcpProducts:   config.DCPProducts,
+		metrics:       newStreamMetrics(config.MetricsHandler),
+		onDrop:        config.OnDrop,
 		subscriptions: make([]string, 0),
+		acks:          make(map[string]*ackWaiter),
+		ackTimeout:    config.AckTimeout,
+		writeTimeout:  config.WriteTimeout,
 	}
 }
 
 func (ws *WebSocket) getWebSocketURL() string {
-	if ws.testnet {
+	if ws.url != "" {
+		return ws.url
+	}
+	path := ws.path
+	if path == "" {
+		path = "/v5/public/" + ws.category
 		if ws.isPrivate {
-			return "wss://stream-testnet.bybit.com/v5/private"
+			path = "/v5/private"
 		}
-		return "wss://stream-testnet.bybit.com/v5/public/spot"
+	}
+	if ws.demo && ws.isPrivate {
+		return DemoWebSocketURL + path
+	}
+	return streamHost(ws.testnet, ws.region) + path
+}
+
+func streamHost(testnet bool, region string) string {
+	if testnet {
+		return "wss://stream-testnet.bybit.com"
 	}
 
-	switch strings.ToLower(ws.region) {
+	switch strings.ToLower(region) {
 	case "nl":
-		if ws.isPrivate {
-			return "wss://stream.bybit.nl/v5/private"
-		}
-		return "wss://stream.bybit.nl/v5/public/spot"
+		return "wss://stream.bybit.nl"
 	case "tr":
-		if ws.isPrivate {
-			return "wss://stream.bybit-tr.com/v5/private"
-		}
-		return "wss://stream.bybit-tr.com/v5/public/spot"
+		return "wss://stream.bybit-tr.com"
 	case "kz":
-		if ws.isPrivate {
-			return "wss://stream.bybit.kz/v5/private"
-		}
-		return "wss://stream.bybit.kz/v5/public/spot"
+		return "wss://stream.bybit.kz"
 	case "ge":
-		if ws.isPrivate {
-			return "wss://stream.bybitgeorgia.ge/v5/private"
-		}
-		return "wss://stream.bybitgeorgia.ge/v5/public/spot"
+		return "wss://stream.bybitgeorgia.ge"
 	case "ae":
-		if ws.isPrivate {
-			return "wss://stream.bybit.ae/v5/private"
-		}
-		return "wss://stream.bybit.ae/v5/public/spot"
+		return "wss://stream.bybit.ae"
 	default:
-		if ws.isPrivate {
-			return "wss://stream.bybit.com/v5/private"
-		}
-		return "wss://stream.bybit.com/v5/public/spot"
+		return "wss://stream.bybit.com"
 	}
 }
 
+// Connect opens the connection and, for private streams, authenticates.
+// It returns ErrAlreadyConnected while a connection is open, so the
+// goroutines of an open connection are never orphaned.
 func (ws *WebSocket) Connect() error {
+	ws.mu.Lock()
+	if ws.connected || ws.dialing {
+		ws.mu.Unlock()
+		return ErrAlreadyConnected
+	}
+	ws.dialing = true
+	ws.mu.Unlock()
+
 	url := ws.getWebSocketURL()
 	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
 	if err != nil {
+		ws.mu.Lock()
+		ws.dialing = false
+		ws.mu.Unlock()
 		return err
 	}
 
+	// One slot beyond inboxSize is reserved for the final error message.
+	inbox := make(chan wsMessage, inboxSize+1)
+	outbox := make(chan wsWrite, outboxSize)
+	done := make(chan struct{})
+
 	ws.mu.Lock()
 	ws.conn = conn
+	ws.inbox = inbox
+	ws.outbox = outbox
+	ws.done = done
 	ws.connected = true
+	ws.dialing = false
 	ws.mu.Unlock()
 
+	ws.metrics.connected()
+
+	go ws.writePump(conn, outbox, done)
+	go ws.readLoop(conn, inbox, done)
+
 	if ws.isPrivate && ws.apiKey != "" && ws.apiSecret != "" {
 		if err := ws.authenticate(); err != nil {
 			ws.Close()
 			return err
 		}
+
+		for _, product := range ws.dcpProducts {
+			if err := ws.SubscribeDCP(product); err != nil {
+				ws.Close()
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -121,51 +253,316 @@ func (ws *WebSocket) authenticate() error {
 	mac.Write([]byte(message))
 	signature := fmt.Sprintf("%x", mac.Sum(nil))
 
-	authMessage := map[string]interface{}{
-		"op":   "auth",
-		"args": []interface{}{ws.apiKey, expires, signature},
+	return ws.request("auth", []interface{}{ws.apiKey, expires, signature})
+}
+
+// readLoop reads frames from conn until it fails. Acknowledgements and
+// pongs are handled here, so Subscribe, auth and ping statistics work even
+// before or without Listen running. Every message is then queued for
+// Listen without blocking. Closing done on exit stops the write pump of the
+// same connection.
+//
+// Frames are read into pooled buffers. In raw mode topic frames are queued
+// undecoded, with the fields needed for metrics peeked from the bytes.
+func (ws *WebSocket) readLoop(conn *websocket.Conn, inbox chan wsMessage, done chan struct{}) {
+	defer close(inbox)
+
+	for {
+		buf := getBuffer()
+		_, reader, err := conn.NextReader()
+		if err == nil {
+			_, err = buf.ReadFrom(reader)
+		}
+		if err != nil {
+			putBuffer(buf)
+
+			ws.mu.Lock()
+			if ws.conn == conn {
+				ws.conn = nil
+				ws.connected = false
+			}
+			raw := ws.rawCallback != nil
+			ws.mu.Unlock()
+
+			close(done)
+			ws.failAcks(err)
+
+			// The reserved slot guarantees room for this message.
+			if raw {
+				errBuf := getBuffer()
+				errBuf.Write(errorFrame(err))
+				inbox <- wsMessage{raw: errBuf}
+			} else {
+				inbox <- wsMessage{data: map[string]interface{}{
+					"error":   true,
+					"message": err.Error(),
+				}}
+			}
+			return
+		}
+
+		received := time.Now()
+		message := buf.Bytes()
+
+		ws.mu.RLock()
+		raw := ws.rawCallback != nil
+		ws.mu.RUnlock()
+
+		if raw {
+			if topic := PeekTopic(message); topic != "" {
+				var updateID int64
+				if strings.HasPrefix(topic, "orderbook.") {
+					if i := peekValue(message, "data"); i >= 0 {
+						updateID = peekInt(message[i:], "u")
+					}
+				}
+				ts := peekInt(message, "ts")
+				if ts == 0 {
+					ts = peekInt(message, "creationTime")
+				}
+				ws.metrics.observeFrame(topic, peekString(message, "type"), ts, updateID, received)
+				ws.enqueue(inbox, wsMessage{raw: buf})
+				continue
+			}
+		}
+
+		var data map[string]interface{}
+		if err := json.Unmarshal(message, &data); err != nil {
+			putBuffer(buf)
+			continue
+		}
+
+		ws.metrics.observe(data, received)
+		ws.resolveAck(data)
+
+		if isServerPing(data) {
+			go ws.Send(map[string]interface{}{"op": "pong"})
+		}
+
+		if raw {
+			ws.enqueue(inbox, wsMessage{raw: buf})
+		} else {
+			putBuffer(buf)
+			ws.enqueue(inbox, wsMessage{data: data})
+		}
 	}
+}
 
-	return ws.Send(authMessage)
+// isServerPing reports whether a frame is a ping sent by the server.
+// Bybit answers our own pings with op "ping" as well, but those replies
+// carry success and ret_msg and must not be answered.
+func isServerPing(data map[string]interface{}) bool {
+	if op, _ := data["op"].(string); op != "ping" {
+		return false
+	}
+	_, reply := data["success"]
+	_, msg := data["ret_msg"]
+	return !reply && !msg
 }
 
-func (ws *WebSocket) Send(message map[string]interface{}) error {
-	ws.mu.RLock()
-	if !ws.connected || ws.conn == nil {
-		ws.mu.RUnlock()
-		if err := ws.Connect(); err != nil {
-			return err
+// enqueue queues a message for Listen, dropping it when the inbox is full.
+// Only readLoop sends on inbox, so the length check cannot race with
+// another sender and the reserved last slot stays free.
+func (ws *WebSocket) enqueue(inbox chan wsMessage, msg wsMessage) {
+	if len(inbox) < inboxSize {
+		inbox <- msg
+		return
+	}
+
+	var topic string
+	if msg.raw != nil {
+		topic = PeekTopic(msg.raw.Bytes())
+		putBuffer(msg.raw)
+	} else {
+		topic, _ = msg.data["topic"].(string)
+	}
+	ws.metrics.dropped()
+	if ws.onDrop != nil {
+		ws.onDrop(topic)
+	}
+}
+
+// request sends an op that the server acknowledges and waits for the
+// matching response. It returns an error when the server rejects the
+// request or does not answer within the ack timeout.
+func (ws *WebSocket) request(op string, args []interface{}) error {
+	reqID := op + "-" + strconv.FormatUint(atomic.AddUint64(&ws.reqSeq, 1), 10)
+	waiter := &ackWaiter{op: op, ch: make(chan map[string]interface{}, 1)}
+
+	ws.mu.Lock()
+	ws.acks[reqID] = waiter
+	ws.mu.Unlock()
+
+	err := ws.Send(map[string]interface{}{
+		"req_id": reqID,
+		"op":     op,
+		"args":   args,
+	})
+	if err != nil {
+		ws.removeAck(reqID)
+		return err
+	}
+
+	timer := time.NewTimer(ws.ackTimeout)
+	defer timer.Stop()
+
+	select {
+	case resp := <-waiter.ch:
+		return ackError(op, resp)
+	case <-timer.C:
+		ws.removeAck(reqID)
+		return fmt.Errorf("%s: no response within %s", op, ws.ackTimeout)
+	}
+}
+
+// resolveAck delivers a response to its pending request. Responses are
+// matched by req_id; auth responses on some endpoints omit it and are
+// matched by op instead.
+func (ws *WebSocket) resolveAck(data map[string]interface{}) {
+	op, _ := data["op"].(string)
+	if op == "" {
+		return
+	}
+	reqID, _ := data["req_id"].(string)
+
+	ws.mu.Lock()
+	waiter, ok := ws.acks[reqID]
+	if !ok && reqID == "" {
+		for id, w := range ws.acks {
+			if w.op == op {
+				reqID, waiter, ok = id, w, true
+				break
+			}
 		}
-		ws.mu.RLock()
 	}
-	conn := ws.conn
+	if ok {
+		delete(ws.acks, reqID)
+	}
+	ws.mu.Unlock()
+
+	if ok {
+		waiter.ch <- data
+	}
+}
+
+func (ws *WebSocket) removeAck(reqID string) {
+	ws.mu.Lock()
+	delete(ws.acks, reqID)
+	ws.mu.Unlock()
+}
+
+func (ws *WebSocket) failAcks(err error) {
+	ws.mu.Lock()
+	acks := ws.acks
+	ws.acks = make(map[string]*ackWaiter)
+	ws.mu.Unlock()
+
+	for _, waiter := range acks {
+		waiter.ch <- map[string]interface{}{"error": err.Error()}
+	}
+}
+
+// ackError converts an acknowledgement into an error. Public and private
+// streams answer with success/ret_msg, the Trade API with retCode/retMsg.
+func ackError(op string, resp map[string]interface{}) error {
+	if msg, ok := resp["error"].(string); ok {
+		return fmt.Errorf("%s: %s", op, msg)
+	}
+	if success, ok := resp["success"].(bool); ok {
+		if success {
+			return nil
+		}
+		return fmt.Errorf("%s rejected: %v", op, resp["ret_msg"])
+	}
+	if code, ok := resp["retCode"].(float64); ok && code != 0 {
+		return fmt.Errorf("%s rejected: %v (retCode %v)", op, resp["retMsg"], code)
+	}
+	return nil
+}
+
+// writePump is the only goroutine writing to conn, as gorilla/websocket
+// allows a single concurrent writer. Frames are written in queue order.
+func (ws *WebSocket) writePump(conn *websocket.Conn, outbox chan wsWrite, done chan struct{}) {
+	for {
+		select {
+		case w := <-outbox:
+			conn.SetWriteDeadline(time.Now().Add(ws.writeTimeout))
+			err := conn.WriteMessage(websocket.TextMessage, w.data)
+			w.result <- err
+			if err != nil {
+				// Closing the connection ends readLoop, which closes done.
+				conn.Close()
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// Send queues a message for the write pump and waits until it is written.
+// It returns ErrNotConnected when the connection is not open; it never
+// reconnects, as a new connection would have no Listen reading it. It is
+// safe to call from multiple goroutines.
+func (ws *WebSocket) Send(message map[string]interface{}) error {
+	ws.mu.RLock()
+	outbox, done := ws.outbox, ws.done
+	connected := ws.connected && ws.conn != nil
 	ws.mu.RUnlock()
 
+	if !connected {
+		return ErrNotConnected
+	}
+
 	data, err := json.Marshal(message)
 	if err != nil {
 		return err
 	}
 
-	return conn.WriteMessage(websocket.TextMessage, data)
+	w := wsWrite{data: data, result: make(chan error, 1)}
+	select {
+	case outbox <- w:
+	case <-done:
+		return errWebSocketClosed
+	}
+
+	select {
+	case err := <-w.result:
+		return err
+	case <-done:
+		return errWebSocketClosed
+	}
 }
 
+// Subscribe subscribes to topics and waits for the server to confirm.
+// Topics are only recorded in GetSubscriptions once confirmed.
 func (ws *WebSocket) Subscribe(topics []string) error {
-	message := map[string]interface{}{
-		"op":   "subscribe",
-		"args": topics,
+	if len(topics) == 0 {
+		return nil
+	}
+
+	if err := ws.request("subscribe", stringArgs(topics)); err != nil {
+		return err
 	}
 
 	ws.mu.Lock()
-	ws.subscriptions = append(ws.subscriptions, topics...)
+	for _, topic := range topics {
+		if !containsString(ws.subscriptions, topic) {
+			ws.subscriptions = append(ws.subscriptions, topic)
+		}
+	}
 	ws.mu.Unlock()
 
-	return ws.Send(message)
+	return nil
 }
 
+// Unsubscribe unsubscribes from topics and waits for the server to confirm.
 func (ws *WebSocket) Unsubscribe(topics []string) error {
-	message := map[string]interface{}{
-		"op":   "unsubscribe",
-		"args": topics,
+	if len(topics) == 0 {
+		return nil
+	}
+
+	if err := ws.request("unsubscribe", stringArgs(topics)); err != nil {
+		return err
 	}
 
 	ws.mu.Lock()
@@ -179,7 +576,35 @@ func (ws *WebSocket) Unsubscribe(topics []string) error {
 	}
 	ws.mu.Unlock()
 
-	return ws.Send(message)
+	return nil
+}
+
+// resetSubscriptions forgets the recorded topics, e.g. after a reconnect
+// when the server no longer holds them, and returns them.
+func (ws *WebSocket) resetSubscriptions() []string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	topics := ws.subscriptions
+	ws.subscriptions = make([]string, 0, len(topics))
+	return topics
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 func (ws *WebSocket) SubscribeOrderbook(symbol string, depth int) error {
@@ -202,6 +627,63 @@ func (ws *WebSocket) SubscribeKline(symbol, interval string) error {
 	return ws.Subscribe([]string{topic})
 }
 
+// SubscribeLiquidation subscribes to allLiquidation.{symbol}, every
+// liquidation of a symbol. Available on the linear and inverse endpoints.
+func (ws *WebSocket) SubscribeLiquidation(symbol string) error {
+	return ws.subscribePublic(fmt.Sprintf("allLiquidation.%s", symbol), CategoryLinear, CategoryInverse)
+}
+
+// SubscribeLTKline subscribes to kline_lt.{interval}.{symbol}, the kline of
+// a leveraged token such as BTC3LUSDT. Available on the spot endpoint.
+func (ws *WebSocket) SubscribeLTKline(symbol, interval string) error {
+	return ws.subscribePublic(fmt.Sprintf("kline_lt.%s.%s", interval, symbol), CategorySpot)
+}
+
+// SubscribeLTTicker subscribes to tickers_lt.{symbol}, the ticker of a
+// leveraged token. Available on the spot endpoint.
+func (ws *WebSocket) SubscribeLTTicker(symbol string) error {
+	return ws.subscribePublic(fmt.Sprintf("tickers_lt.%s", symbol), CategorySpot)
+}
+
+// SubscribeLTNav subscribes to lt.{symbol}, the net asset value of a
+// leveraged token. Available on the spot endpoint.
+func (ws *WebSocket) SubscribeLTNav(symbol string) error {
+	return ws.subscribePublic(fmt.Sprintf("lt.%s", symbol), CategorySpot)
+}
+
+// SubscribeRPIOrderbook subscribes to orderbook.rpi.{symbol}, the order book
+// including Retail Price Improvement orders. Available on the spot, linear
+// and inverse endpoints.
+func (ws *WebSocket) SubscribeRPIOrderbook(symbol string) error {
+	return ws.subscribePublic(fmt.Sprintf("orderbook.rpi.%s", symbol), CategorySpot, CategoryLinear, CategoryInverse)
+}
+
+// SubscribeInsurancePool subscribes to insurance.{coin}, the insurance pool
+// balance. Use USDT or USDC on the linear endpoint and "inverse" on the
+// inverse endpoint.
+func (ws *WebSocket) SubscribeInsurancePool(coin string) error {
+	return ws.subscribePublic(fmt.Sprintf("insurance.%s", coin), CategoryLinear, CategoryInverse)
+}
+
+// SubscribePriceLimit subscribes to priceLimit.{symbol}, the highest buy and
+// lowest sell price an order may be placed at. Available on the spot,
+// linear and inverse endpoints.
+func (ws *WebSocket) SubscribePriceLimit(symbol string) error {
+	return ws.subscribePublic(fmt.Sprintf("priceLimit.%s", symbol), CategorySpot, CategoryLinear, CategoryInverse)
+}
+
+// subscribePublic subscribes to a public topic after checking that this
+// connection's endpoint serves it.
+func (ws *WebSocket) subscribePublic(topic string, categories ...string) error {
+	if ws.isPrivate {
+		return fmt.Errorf("%s is a public topic, use a public connection", topic)
+	}
+	if !containsString(categories, ws.category) {
+		return fmt.Errorf("%s is not available on the %s endpoint, use %s", topic, ws.category, strings.Join(categories, " or "))
+	}
+	return ws.Subscribe([]string{topic})
+}
+
 func (ws *WebSocket) SubscribePosition() error {
 	return ws.Subscribe([]string{"position"})
 }
@@ -218,12 +700,74 @@ func (ws *WebSocket) SubscribeWallet() error {
 	return ws.Subscribe([]string{"wallet"})
 }
 
+// SubscribeFastExecution subscribes to execution.fast, a lower latency
+// execution feed with fewer fields. Pass a category (spot, linear, inverse,
+// option) to receive a single category, or "" for all.
+func (ws *WebSocket) SubscribeFastExecution(category string) error {
+	return ws.subscribePrivate(categoryTopic("execution.fast", category))
+}
+
+// SubscribePositionByCategory subscribes to position.{category}.
+func (ws *WebSocket) SubscribePositionByCategory(category string) error {
+	return ws.subscribePrivate(categoryTopic("position", category))
+}
+
+// SubscribeExecutionByCategory subscribes to execution.{category}.
+func (ws *WebSocket) SubscribeExecutionByCategory(category string) error {
+	return ws.subscribePrivate(categoryTopic("execution", category))
+}
+
+// SubscribeOrderByCategory subscribes to order.{category}.
+func (ws *WebSocket) SubscribeOrderByCategory(category string) error {
+	return ws.subscribePrivate(categoryTopic("order", category))
+}
+
+// SubscribeGreeks subscribes to greeks, the account's option greeks per
+// base coin.
+func (ws *WebSocket) SubscribeGreeks() error {
+	return ws.subscribePrivate("greeks")
+}
+
+// SubscribeDCP subscribes to the Disconnection Protect status of a product:
+// DCPProductFutures, DCPProductSpot or DCPProductOptions.
+func (ws *WebSocket) SubscribeDCP(product string) error {
+	return ws.subscribePrivate("dcp." + strings.ToLower(product))
+}
+
+func (ws *WebSocket) subscribePrivate(topic string) error {
+	if !ws.isPrivate {
+		return fmt.Errorf("%s is a private topic, use a private connection", topic)
+	}
+	return ws.Subscribe([]string{topic})
+}
+
+func categoryTopic(topic, category string) string {
+	if category == "" {
+		return topic
+	}
+	return topic + "." + strings.ToLower(category)
+}
+
 func (ws *WebSocket) OnMessage(callback func(map[string]interface{})) {
 	ws.mu.Lock()
 	ws.messageCallback = callback
 	ws.mu.Unlock()
 }
 
+// OnRawMessage switches the connection to raw mode: Listen passes every
+// frame to callback as undecoded JSON bytes instead of decoding it into a
+// map for OnMessage. The slice is only valid during the call, as its
+// buffer is reused afterwards. Pair it with StreamDecoder for typed
+// decoding. Pass nil to return to map decoding.
+func (ws *WebSocket) OnRawMessage(callback func([]byte)) {
+	ws.mu.Lock()
+	ws.rawCallback = callback
+	ws.mu.Unlock()
+}
+
+// Listen delivers received messages to the OnMessage callback until the
+// connection is closed. A final {"error": true, "message": ...} message is
+// delivered when reading fails.
 func (ws *WebSocket) Listen() error {
 	ws.mu.RLock()
 	if !ws.connected || ws.conn == nil {
@@ -231,49 +775,32 @@ func (ws *WebSocket) Listen() error {
 		if err := ws.Connect(); err != nil {
 			return err
 		}
-	} else {
-		ws.mu.RUnlock()
+		ws.mu.RLock()
 	}
+	inbox := ws.inbox
+	ws.mu.RUnlock()
 
-	for {
+	for msg := range inbox {
 		ws.mu.RLock()
-		conn := ws.conn
+		callback := ws.messageCallback
+		rawCallback := ws.rawCallback
 		ws.mu.RUnlock()
 
-		if conn == nil {
-			break
-		}
-
-		_, message, err := conn.ReadMessage()
-		if err != nil {
-			ws.mu.RLock()
-			callback := ws.messageCallback
-			ws.mu.RUnlock()
-
-			if callback != nil {
-				callback(map[string]interface{}{
-					"error":   true,
-					"message": err.Error(),
-				})
+		if msg.raw != nil {
+			if rawCallback != nil {
+				rawCallback(msg.raw.Bytes())
+			} else if callback != nil {
+				var data map[string]interface{}
+				if err := json.Unmarshal(msg.raw.Bytes(), &data); err == nil {
+					callback(data)
+				}
 			}
-			break
-		}
-
-		var data map[string]interface{}
-		if err := json.Unmarshal(message, &data); err != nil {
+			putBuffer(msg.raw)
 			continue
 		}
 
-		ws.mu.RLock()
-		callback := ws.messageCallback
-		ws.mu.RUnlock()
-
 		if callback != nil {
-			callback(data)
-		}
-
-		if op, ok := data["op"].(string); ok && op == "ping" {
-			ws.Send(map[string]interface{}{"op": "pong"})
+			callback(msg.data)
 		}
 	}
 
@@ -281,9 +808,21 @@ func (ws *WebSocket) Listen() error {
 }
 
 func (ws *WebSocket) Ping() error {
+	ws.metrics.pingSentAt(time.Now())
 	return ws.Send(map[string]interface{}{"op": "ping"})
 }
 
+// Metrics returns a snapshot of per-topic message rates, latency
+// percentiles and sequence gaps, plus connection and ping statistics.
+func (ws *WebSocket) Metrics() StreamMetrics {
+	return ws.metrics.snapshot()
+}
+
+// ResetMetrics clears the per-topic metrics. Connection counts are kept.
+func (ws *WebSocket) ResetMetrics() {
+	ws.metrics.reset()
+}
+
 func (ws *WebSocket) Close() error {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
@@ -0,0 +1,261 @@
+package bybit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeStream serves websocket connections, passing every frame received on
+// a connection to handle. handle runs on the connection's read goroutine,
+// so it is the only writer of conn.
+func fakeStream(t *testing.T, handle func(conn *websocket.Conn, msg map[string]interface{})) *httptest.Server {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var msg map[string]interface{}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			handle(conn, msg)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// fakeWebSocket returns a WebSocket dialing srv.
+func fakeWebSocket(srv *httptest.Server, config WebSocketConfig) *WebSocket {
+	ws := NewWebSocket(config)
+	ws.url = "ws" + strings.TrimPrefix(srv.URL, "http")
+	return ws
+}
+
+func TestWebSocketAcksMatchedByReqID(t *testing.T) {
+	const n = 20
+
+	// Hold the requests and answer them in reverse order, rejecting the
+	// topics marked bad, so only req_id can pair them up.
+	var pending []map[string]interface{}
+	srv := fakeStream(t, func(conn *websocket.Conn, msg map[string]interface{}) {
+		pending = append(pending, msg)
+		if len(pending) < n {
+			return
+		}
+		for i := len(pending) - 1; i >= 0; i-- {
+			req := pending[i]
+			topic := req["args"].([]interface{})[0].(string)
+			conn.WriteJSON(map[string]interface{}{
+				"op":      req["op"],
+				"req_id":  req["req_id"],
+				"success": !strings.HasPrefix(topic, "bad."),
+				"ret_msg": topic,
+			})
+		}
+	})
+
+	ws := fakeWebSocket(srv, WebSocketConfig{AckTimeout: 5 * time.Second})
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		topic := "good." + string(rune('a'+i))
+		if i%2 == 1 {
+			topic = "bad." + string(rune('a'+i))
+		}
+		wg.Add(1)
+		go func(i int, topic string) {
+			defer wg.Done()
+			errs[i] = ws.Subscribe([]string{topic})
+		}(i, topic)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if bad := i%2 == 1; bad != (err != nil) {
+			t.Errorf("subscribe %d: err = %v, want rejected %v", i, err, bad)
+		}
+	}
+	if got := len(ws.GetSubscriptions()); got != n/2 {
+		t.Errorf("subscriptions = %d, want %d", got, n/2)
+	}
+}
+
+func TestWebSocketAcksFailOnDisconnect(t *testing.T) {
+	srv := fakeStream(t, func(conn *websocket.Conn, msg map[string]interface{}) {
+		conn.Close()
+	})
+
+	ws := fakeWebSocket(srv, WebSocketConfig{AckTimeout: 5 * time.Second})
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := ws.Subscribe([]string{"tickers.BTCUSDT"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("subscribe waited %s for the ack timeout", elapsed)
+	}
+	if err := ws.Send(map[string]interface{}{"op": "ping"}); err == nil {
+		t.Error("expected an error sending on a dropped connection")
+	}
+}
+
+func TestWebSocketConnectTwice(t *testing.T) {
+	srv := fakeStream(t, func(conn *websocket.Conn, msg map[string]interface{}) {})
+
+	ws := fakeWebSocket(srv, WebSocketConfig{})
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.Connect(); err != ErrAlreadyConnected {
+		t.Errorf("second Connect = %v, want ErrAlreadyConnected", err)
+	}
+
+	ws.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		err := ws.Connect()
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("reconnect after Close: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	ws.Close()
+}
+
+func TestWebSocketReportsDrops(t *testing.T) {
+	const extra = 5
+
+	srv := fakeStream(t, func(conn *websocket.Conn, msg map[string]interface{}) {
+		for i := 0; i < inboxSize+extra; i++ {
+			conn.WriteJSON(map[string]interface{}{"topic": "publicTrade.BTCUSDT", "ts": 1})
+		}
+	})
+
+	dropped := make(chan string, extra)
+	ws := fakeWebSocket(srv, WebSocketConfig{OnDrop: func(topic string) { dropped <- topic }})
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	// Nothing listens, so everything beyond the inbox is dropped.
+	if err := ws.Send(map[string]interface{}{"op": "start"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < extra; i++ {
+		select {
+		case topic := <-dropped:
+			if topic != "publicTrade.BTCUSDT" {
+				t.Errorf("dropped topic = %q", topic)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%d drops reported, want %d", i, extra)
+		}
+	}
+	if got := ws.Metrics().Dropped; got != extra {
+		t.Errorf("Dropped = %d, want %d", got, extra)
+	}
+}
+
+func TestWebSocketConcurrentSends(t *testing.T) {
+	const senders, perSender = 8, 50
+
+	var mu sync.Mutex
+	seen := make(map[float64]int)
+	srv := fakeStream(t, func(conn *websocket.Conn, msg map[string]interface{}) {
+		mu.Lock()
+		seen[msg["n"].(float64)]++
+		mu.Unlock()
+	})
+
+	ws := fakeWebSocket(srv, WebSocketConfig{})
+	if err := ws.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	var wg sync.WaitGroup
+	for s := 0; s < senders; s++ {
+		wg.Add(1)
+		go func(s int) {
+			defer wg.Done()
+			for i := 0; i < perSender; i++ {
+				n := s*perSender + i
+				if err := ws.Send(map[string]interface{}{"op": "test", "n": n}); err != nil {
+					t.Errorf("send %d: %v", n, err)
+					return
+				}
+			}
+		}(s)
+	}
+	wg.Wait()
+
+	// Send returns once a frame is written, not once it is read.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		got := len(seen)
+		mu.Unlock()
+		if got == senders*perSender || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != senders*perSender {
+		t.Fatalf("server read %d distinct frames, want %d", len(seen), senders*perSender)
+	}
+	for n, count := range seen {
+		if count != 1 {
+			t.Errorf("frame %v read %d times", n, count)
+		}
+	}
+}
+
+func TestIsServerPing(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want bool
+	}{
+		{"server ping", map[string]interface{}{"op": "ping"}, true},
+		{"public pong reply", map[string]interface{}{"op": "ping", "success": true, "ret_msg": "pong", "conn_id": "1"}, false},
+		{"reply without success", map[string]interface{}{"op": "ping", "ret_msg": "pong"}, false},
+		{"private pong", map[string]interface{}{"op": "pong", "args": []interface{}{"1"}}, false},
+		{"subscribe ack", map[string]interface{}{"op": "subscribe", "success": true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isServerPing(tt.data); got != tt.want {
+				t.Errorf("isServerPing = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}